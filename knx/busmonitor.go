@@ -0,0 +1,193 @@
+package knx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/vapourismo/knx-go/knx/cemi"
+)
+
+// cemiAddInfoTimestamp is the cEMI additional information type carrying a
+// frame's relative timestamp: a free-running tick, in microseconds, that
+// wraps around at 65536. See the cEMI specification's additional info table.
+const cemiAddInfoTimestamp = 0x04
+
+// A BusmonFrame is a single L_Busmon.ind indication captured from a bus
+// monitor tunnel, together with the additional information (timestamp, bus
+// status) the gateway attached to it.
+type BusmonFrame struct {
+	// Timestamp is derived from the gateway's relative timestamp additional
+	// info rather than our own receive time, so that frames captured back to
+	// back keep their original spacing even under host scheduling jitter. It
+	// falls back to the local receive time for the first frame of a session
+	// and for any frame missing the additional info.
+	Timestamp time.Time
+
+	// Status is the bus status byte reported alongside the frame.
+	Status uint8
+
+	// Raw is the complete serialized cEMI L_Busmon.ind frame, including its
+	// message code and additional info block.
+	Raw []byte
+}
+
+// A BusMonitor is a tunnel connection operating in TunnelLayerBusmon, giving
+// read-only access to every frame observed on the bus. It is the scriptable
+// equivalent of ETS's Group Monitor.
+type BusMonitor struct {
+	tunnel *Tunnel
+	frames chan BusmonFrame
+	closed chan struct{}
+
+	tsMu     sync.Mutex
+	haveTick bool
+	lastTick uint16
+	lastTime time.Time
+}
+
+// NewBusMonitor opens a bus monitor tunnel with the given gateway.
+func NewBusMonitor(gatewayAddr string, config TunnelConfig) (*BusMonitor, error) {
+	tunnel, err := NewTunnel(gatewayAddr, TunnelLayerBusmon, config)
+	if err != nil {
+		return nil, err
+	}
+
+	monitor := &BusMonitor{
+		tunnel: tunnel,
+		frames: make(chan BusmonFrame),
+		closed: make(chan struct{}),
+	}
+
+	go monitor.serve()
+
+	return monitor, nil
+}
+
+// serve relays L_Busmon.ind frames from the underlying tunnel to the
+// monitor's frame channel.
+func (monitor *BusMonitor) serve() {
+	defer close(monitor.frames)
+
+	for msg := range monitor.tunnel.Inbound() {
+		ind, ok := msg.(*cemi.BusmonInd)
+		if !ok {
+			continue
+		}
+
+		var buf bytes.Buffer
+		if _, err := ind.WriteTo(&buf); err != nil {
+			continue
+		}
+
+		frame := BusmonFrame{
+			Timestamp: monitor.timestampFor(buf.Bytes()),
+			Status:    ind.Status,
+			Raw:       buf.Bytes(),
+		}
+
+		select {
+		case monitor.frames <- frame:
+		case <-monitor.closed:
+			return
+		}
+	}
+}
+
+// timestampFor derives a frame's capture time from the gateway's relative
+// timestamp additional info, anchored against the previous frame's tick and
+// local receive time. This keeps consecutive frames' spacing accurate to the
+// gateway's own clock instead of ours. It falls back to the local receive
+// time if raw carries no timestamp additional info, which also reanchors the
+// sequence for the next frame.
+func (monitor *BusMonitor) timestampFor(raw []byte) time.Time {
+	now := time.Now()
+
+	tick, ok := busmonTimestamp(raw)
+	if !ok {
+		monitor.tsMu.Lock()
+		monitor.haveTick = false
+		monitor.tsMu.Unlock()
+
+		return now
+	}
+
+	monitor.tsMu.Lock()
+	defer monitor.tsMu.Unlock()
+
+	if monitor.haveTick {
+		now = monitor.lastTime.Add(time.Duration(tick-monitor.lastTick) * time.Microsecond)
+	}
+
+	monitor.lastTick = tick
+	monitor.lastTime = now
+	monitor.haveTick = true
+
+	return now
+}
+
+// busmonTimestamp scans a serialized L_Busmon.ind frame's additional info
+// block for the relative timestamp entry and returns its tick value.
+func busmonTimestamp(raw []byte) (uint16, bool) {
+	if len(raw) < 2 {
+		return 0, false
+	}
+
+	ail := int(raw[1])
+	if len(raw) < 2+ail {
+		return 0, false
+	}
+
+	info := raw[2 : 2+ail]
+	for len(info) >= 2 {
+		typ, length := info[0], int(info[1])
+		if len(info) < 2+length {
+			break
+		}
+
+		if typ == cemiAddInfoTimestamp && length == 2 {
+			return binary.BigEndian.Uint16(info[2:4]), true
+		}
+
+		info = info[2+length:]
+	}
+
+	return 0, false
+}
+
+// Frames returns the channel on which captured bus monitor frames are
+// delivered.
+func (monitor *BusMonitor) Frames() <-chan BusmonFrame {
+	return monitor.frames
+}
+
+// Close terminates the bus monitor tunnel.
+func (monitor *BusMonitor) Close() {
+	select {
+	case <-monitor.closed:
+	default:
+		close(monitor.closed)
+	}
+
+	monitor.tunnel.Close()
+}
+
+// WritePcap consumes frames from the monitor and writes them to w as a pcap
+// capture using LINKTYPE_USER0, the libpcap link type reserved for private
+// encapsulations (see dltKNXLinkLayer). It returns once the monitor's frame
+// channel is closed or writing fails.
+func (monitor *BusMonitor) WritePcap(w io.Writer) error {
+	if err := writePcapGlobalHeader(w); err != nil {
+		return err
+	}
+
+	for frame := range monitor.frames {
+		if err := writePcapRecord(w, frame); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
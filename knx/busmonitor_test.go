@@ -0,0 +1,48 @@
+package knx
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBusmonTimestampExtractsTick(t *testing.T) {
+	// Message code, AIL=4, additional info block: type=Timestamp, length=2, tick=0x1234.
+	raw := []byte{0x2b, 0x04, cemiAddInfoTimestamp, 0x02, 0x12, 0x34, 0x00 /* rest of frame */}
+
+	tick, ok := busmonTimestamp(raw)
+	if !ok {
+		t.Fatal("busmonTimestamp did not find the timestamp additional info")
+	}
+
+	if tick != 0x1234 {
+		t.Fatalf("tick = %#x, want 0x1234", tick)
+	}
+}
+
+func TestBusmonTimestampMissingAdditionalInfo(t *testing.T) {
+	raw := []byte{0x2b, 0x00, 0xaa, 0xbb}
+
+	if _, ok := busmonTimestamp(raw); ok {
+		t.Fatal("busmonTimestamp reported success for a frame with no additional info")
+	}
+}
+
+func TestBusmonTimestampTruncatedFrame(t *testing.T) {
+	if _, ok := busmonTimestamp([]byte{0x2b}); ok {
+		t.Fatal("busmonTimestamp reported success for a truncated frame")
+	}
+}
+
+func TestTimestampForAnchorsOnGatewayTicks(t *testing.T) {
+	monitor := &BusMonitor{}
+
+	first := []byte{0x2b, 0x04, cemiAddInfoTimestamp, 0x02, 0x00, 0x64}  // tick = 100
+	second := []byte{0x2b, 0x04, cemiAddInfoTimestamp, 0x02, 0x00, 0xc8} // tick = 200
+
+	t1 := monitor.timestampFor(first)
+	t2 := monitor.timestampFor(second)
+
+	if delta := t2.Sub(t1); delta != 100*time.Microsecond {
+		t.Fatalf("delta between frames = %v, want 100us", delta)
+	}
+}
@@ -0,0 +1,167 @@
+package knx
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/vapourismo/knx-go/knx/proto"
+)
+
+// A GatewayInfo describes a KNXnet/IP gateway discovered on the network.
+type GatewayInfo struct {
+	// Address is the gateway's control endpoint, as advertised in its
+	// SearchRes.
+	Address *net.UDPAddr
+
+	// IndividualAddress is the gateway's own KNX individual address.
+	IndividualAddress uint16
+
+	// MAC is the gateway's MAC address.
+	MAC [6]byte
+
+	// FriendlyName is the human-readable name configured for the gateway.
+	FriendlyName string
+
+	// ServiceFamilies lists the KNXnet/IP service families the gateway
+	// advertises support for, e.g. Tunnelling or Routing, with their
+	// versions.
+	ServiceFamilies []proto.ServiceFamily
+}
+
+// String renders the gateway's name, individual address, and control
+// endpoint for logging.
+func (info GatewayInfo) String() string {
+	return fmt.Sprintf("%s (%s) at %s", info.FriendlyName, formatIndividualAddress(info.IndividualAddress), info.Address)
+}
+
+// formatIndividualAddress renders a KNX individual address in area.line.device form.
+func formatIndividualAddress(addr uint16) string {
+	return fmt.Sprintf("%d.%d.%d", addr>>12, (addr>>8)&0xf, addr&0xff)
+}
+
+// Discover multicasts a SearchReq on the KNXnet/IP routing multicast group
+// and collects every SearchRes received until ctx is cancelled or its
+// deadline expires. iface selects the network interface to multicast on; an
+// empty string uses the system default.
+func Discover(ctx context.Context, iface string) ([]GatewayInfo, error) {
+	groupAddr, err := net.ResolveUDPAddr("udp4", routingMulticastAddr)
+	if err != nil {
+		return nil, fmt.Errorf("knx: resolving discovery multicast address: %w", err)
+	}
+
+	var netIface *net.Interface
+	if iface != "" {
+		netIface, err = net.InterfaceByName(iface)
+		if err != nil {
+			return nil, fmt.Errorf("knx: resolving interface %q: %w", iface, err)
+		}
+	}
+
+	conn, err := net.ListenMulticastUDP("udp4", netIface, groupAddr)
+	if err != nil {
+		return nil, fmt.Errorf("knx: listening for discovery responses: %w", err)
+	}
+	defer conn.Close()
+
+	sendConn, err := net.DialUDP("udp4", nil, groupAddr)
+	if err != nil {
+		return nil, fmt.Errorf("knx: opening discovery send socket: %w", err)
+	}
+	defer sendConn.Close()
+
+	if err := sendFrame(sendConn, &proto.SearchReq{}); err != nil {
+		return nil, fmt.Errorf("knx: sending SearchReq: %w", err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		conn.SetReadDeadline(time.Now())
+	}()
+
+	var gateways []GatewayInfo
+
+	for {
+		frame, from, err := readDiscoveryFrame(conn)
+		if err != nil {
+			if ctx.Err() != nil {
+				return gateways, nil
+			}
+
+			if errors.Is(err, errUnexpectedService) {
+				continue
+			}
+
+			return gateways, err
+		}
+
+		var res proto.SearchRes
+		if _, err := res.Unpack(frame); err != nil {
+			continue
+		}
+
+		gateways = append(gateways, GatewayInfo{
+			Address:           controlAddr(res.Control, from),
+			IndividualAddress: res.Device.IndividualAddress,
+			MAC:               res.Device.MAC,
+			FriendlyName:      friendlyName(res.Device.FriendlyName),
+			ServiceFamilies:   res.SuppSvc.Families,
+		})
+	}
+}
+
+// controlAddr resolves a SearchRes's advertised control endpoint, falling
+// back to the datagram's source address if the gateway advertised the
+// unspecified address, as happens when it doesn't know its own address,
+// e.g. behind NAT.
+func controlAddr(info proto.HostInfo, from *net.UDPAddr) *net.UDPAddr {
+	ip := net.IP(info.Address[:])
+	if ip.IsUnspecified() {
+		return from
+	}
+
+	return &net.UDPAddr{IP: ip, Port: int(info.Port)}
+}
+
+// errUnexpectedService marks a frame that parsed fine but isn't a SearchRes.
+// The routing multicast group Discover listens on also carries regular
+// ROUTING_INDICATION traffic, so such frames are routine, not failures.
+var errUnexpectedService = errors.New("knx: unexpected service in discovery frame")
+
+// readDiscoveryFrame reads a single KNXnet/IP frame carrying a SearchRes and
+// returns its body along with the sender's address. It returns
+// errUnexpectedService for any well-formed frame that isn't a SearchRes.
+func readDiscoveryFrame(conn *net.UDPConn) ([]byte, *net.UDPAddr, error) {
+	buf := make([]byte, 1024)
+
+	n, from, err := conn.ReadFromUDP(buf)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if n < frameHeaderLen {
+		return nil, nil, errUnexpectedService
+	}
+
+	service := proto.ServiceID(binary.BigEndian.Uint16(buf[2:4]))
+	if service != proto.SearchResService {
+		return nil, nil, errUnexpectedService
+	}
+
+	return buf[frameHeaderLen:n], from, nil
+}
+
+// friendlyName trims the trailing NUL padding from a Device Info DIB's
+// friendly name field.
+func friendlyName(raw [30]byte) string {
+	end := bytes.IndexByte(raw[:], 0)
+	if end < 0 {
+		end = len(raw)
+	}
+
+	return string(raw[:end])
+}
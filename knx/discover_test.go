@@ -0,0 +1,30 @@
+package knx
+
+import "testing"
+
+func TestFormatIndividualAddress(t *testing.T) {
+	got := formatIndividualAddress(0x11ff)
+	if want := "1.1.255"; got != want {
+		t.Fatalf("formatIndividualAddress(0x11ff) = %q, want %q", got, want)
+	}
+}
+
+func TestFriendlyNameTrimsPadding(t *testing.T) {
+	var raw [30]byte
+	copy(raw[:], "my gateway")
+
+	if got, want := friendlyName(raw), "my gateway"; got != want {
+		t.Fatalf("friendlyName = %q, want %q", got, want)
+	}
+}
+
+func TestFriendlyNameFullyPadded(t *testing.T) {
+	var raw [30]byte
+	for i := range raw {
+		raw[i] = 'x'
+	}
+
+	if got, want := friendlyName(raw), string(raw[:]); got != want {
+		t.Fatalf("friendlyName = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,118 @@
+// Package prometheus provides a ready-to-use knx.Observer that exports
+// connection lifecycle metrics in Prometheus format.
+package prometheus
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/vapourismo/knx-go/knx"
+	"github.com/vapourismo/knx-go/knx/proto"
+)
+
+// Observer is a knx.Observer that records connection attempts, heartbeat
+// RTT and failures, disconnect reasons, and session durations as Prometheus
+// metrics. Register it once with a prometheus.Registerer and pass it as
+// TunnelConfig.Observer for every tunnel to be instrumented.
+type Observer struct {
+	ConnectAttempts  *prometheus.CounterVec
+	HeartbeatRTT     prometheus.Histogram
+	HeartbeatFailure *prometheus.CounterVec
+	Disconnects      *prometheus.CounterVec
+	SessionDuration  *prometheus.HistogramVec
+}
+
+// NewObserver creates an Observer and registers its metrics with reg.
+func NewObserver(reg prometheus.Registerer) *Observer {
+	observer := &Observer{
+		ConnectAttempts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "knx",
+			Subsystem: "tunnel",
+			Name:      "connect_attempts_total",
+			Help:      "Number of ConnReq/ConnRes exchanges, labelled by outcome.",
+		}, []string{"gateway", "status"}),
+
+		HeartbeatRTT: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "knx",
+			Subsystem: "tunnel",
+			Name:      "heartbeat_rtt_seconds",
+			Help:      "Round-trip time of ConnStateReq/ConnStateRes exchanges.",
+			Buckets:   prometheus.DefBuckets,
+		}),
+
+		HeartbeatFailure: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "knx",
+			Subsystem: "tunnel",
+			Name:      "heartbeat_failures_total",
+			Help:      "Number of failed heartbeats, labelled by connection state.",
+		}, []string{"gateway", "state"}),
+
+		Disconnects: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "knx",
+			Subsystem: "tunnel",
+			Name:      "disconnects_total",
+			Help:      "Number of DiscReq/DiscRes exchanges, labelled by status.",
+		}, []string{"gateway", "status"}),
+
+		SessionDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "knx",
+			Subsystem: "tunnel",
+			Name:      "session_duration_seconds",
+			Help:      "Duration a tunnel channel stayed connected, labelled by tunnelling layer.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 16),
+		}, []string{"gateway", "layer"}),
+	}
+
+	reg.MustRegister(
+		observer.ConnectAttempts,
+		observer.HeartbeatRTT,
+		observer.HeartbeatFailure,
+		observer.Disconnects,
+		observer.SessionDuration,
+	)
+
+	return observer
+}
+
+// OnConnect implements knx.Observer.
+func (o *Observer) OnConnect(gateway string, _ knx.TunnelLayer, status proto.ConnResStatus, _ time.Duration) {
+	o.ConnectAttempts.WithLabelValues(gateway, status.String()).Inc()
+}
+
+// OnHeartbeat implements knx.Observer.
+func (o *Observer) OnHeartbeat(gateway string, _ uint8, state proto.ConnState, rtt time.Duration, err error) {
+	o.HeartbeatRTT.Observe(rtt.Seconds())
+
+	if err != nil {
+		o.HeartbeatFailure.WithLabelValues(gateway, state.String()).Inc()
+	}
+}
+
+// OnDisconnect implements knx.Observer.
+func (o *Observer) OnDisconnect(gateway string, _ uint8, status uint8) {
+	o.Disconnects.WithLabelValues(gateway, strconv.Itoa(int(status))).Inc()
+}
+
+// OnSessionEnd implements knx.Observer.
+func (o *Observer) OnSessionEnd(gateway string, layer knx.TunnelLayer, duration time.Duration) {
+	o.SessionDuration.WithLabelValues(gateway, layerName(layer)).Observe(duration.Seconds())
+}
+
+// layerName renders a TunnelLayer as a label value.
+func layerName(layer knx.TunnelLayer) string {
+	switch layer {
+	case knx.TunnelLayerData:
+		return "data"
+
+	case knx.TunnelLayerRaw:
+		return "raw"
+
+	case knx.TunnelLayerBusmon:
+		return "busmon"
+
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,46 @@
+package knx
+
+import (
+	"time"
+
+	"github.com/vapourismo/knx-go/knx/proto"
+)
+
+// An Observer is notified about events in a tunnel connection's lifecycle.
+// Implementations must be safe for concurrent use; Tunnel invokes them from
+// whichever goroutine drives the connection's state machine. Set
+// TunnelConfig.Observer to receive these events; the zero value disables
+// instrumentation.
+type Observer interface {
+	// OnConnect is called after a ConnReq/ConnRes exchange completes,
+	// successfully or not.
+	OnConnect(gateway string, layer TunnelLayer, status proto.ConnResStatus, elapsed time.Duration)
+
+	// OnHeartbeat is called after every ConnStateReq/ConnStateRes exchange.
+	// err is non-nil if the heartbeat timed out.
+	OnHeartbeat(gateway string, channel uint8, state proto.ConnState, rtt time.Duration, err error)
+
+	// OnDisconnect is called when a channel is torn down, whether initiated
+	// locally or by the gateway.
+	OnDisconnect(gateway string, channel uint8, status uint8)
+
+	// OnSessionEnd is called once a channel's connection finally closes,
+	// reporting how long it was open for.
+	OnSessionEnd(gateway string, layer TunnelLayer, duration time.Duration)
+}
+
+// NopObserver is an Observer that discards every event. It is the default
+// used when TunnelConfig.Observer is nil.
+type NopObserver struct{}
+
+// OnConnect implements Observer.
+func (NopObserver) OnConnect(string, TunnelLayer, proto.ConnResStatus, time.Duration) {}
+
+// OnHeartbeat implements Observer.
+func (NopObserver) OnHeartbeat(string, uint8, proto.ConnState, time.Duration, error) {}
+
+// OnDisconnect implements Observer.
+func (NopObserver) OnDisconnect(string, uint8, uint8) {}
+
+// OnSessionEnd implements Observer.
+func (NopObserver) OnSessionEnd(string, TunnelLayer, time.Duration) {}
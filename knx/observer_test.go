@@ -0,0 +1,8 @@
+package knx
+
+// Compile-time assertions that NopObserver and the tests' recordingObserver
+// satisfy the interface Tunnel invokes.
+var (
+	_ Observer = NopObserver{}
+	_ Observer = (*recordingObserver)(nil)
+)
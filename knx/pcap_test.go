@@ -0,0 +1,62 @@
+package knx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+func TestWritePcapGlobalHeader(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writePcapGlobalHeader(&buf); err != nil {
+		t.Fatalf("writePcapGlobalHeader: %v", err)
+	}
+
+	header := buf.Bytes()
+	if len(header) != 24 {
+		t.Fatalf("len(header) = %d, want 24", len(header))
+	}
+
+	if magic := binary.LittleEndian.Uint32(header[0:4]); magic != 0xa1b2c3d4 {
+		t.Fatalf("magic number = %#x, want 0xa1b2c3d4", magic)
+	}
+
+	if dlt := binary.LittleEndian.Uint32(header[20:24]); dlt != dltKNXLinkLayer {
+		t.Fatalf("link type = %d, want %d", dlt, dltKNXLinkLayer)
+	}
+}
+
+func TestWritePcapRecord(t *testing.T) {
+	frame := BusmonFrame{
+		Timestamp: time.Unix(1700000000, 123000),
+		Status:    0,
+		Raw:       []byte{0x2b, 0x00, 0xaa},
+	}
+
+	var buf bytes.Buffer
+	if err := writePcapRecord(&buf, frame); err != nil {
+		t.Fatalf("writePcapRecord: %v", err)
+	}
+
+	record := buf.Bytes()
+	if len(record) != 16+len(frame.Raw) {
+		t.Fatalf("len(record) = %d, want %d", len(record), 16+len(frame.Raw))
+	}
+
+	if sec := binary.LittleEndian.Uint32(record[0:4]); sec != uint32(frame.Timestamp.Unix()) {
+		t.Fatalf("seconds = %d, want %d", sec, frame.Timestamp.Unix())
+	}
+
+	if usec := binary.LittleEndian.Uint32(record[4:8]); usec != uint32(frame.Timestamp.Nanosecond()/1000) {
+		t.Fatalf("microseconds = %d, want %d", usec, frame.Timestamp.Nanosecond()/1000)
+	}
+
+	if capLen := binary.LittleEndian.Uint32(record[8:12]); capLen != uint32(len(frame.Raw)) {
+		t.Fatalf("captured length = %d, want %d", capLen, len(frame.Raw))
+	}
+
+	if !bytes.Equal(record[16:], frame.Raw) {
+		t.Fatalf("payload = %x, want %x", record[16:], frame.Raw)
+	}
+}
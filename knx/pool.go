@@ -0,0 +1,457 @@
+package knx
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/vapourismo/knx-go/knx/cemi"
+	"github.com/vapourismo/knx-go/knx/proto"
+)
+
+// PoolStrategy selects how a TunnelPool picks a channel for an outgoing
+// request.
+type PoolStrategy int
+
+const (
+	// RoundRobin cycles through the pool's channels in order.
+	RoundRobin PoolStrategy = iota
+
+	// LeastLoaded picks the channel with the fewest in-flight requests.
+	LeastLoaded
+)
+
+// PoolConfig configures a TunnelPool.
+type PoolConfig struct {
+	// Gateways lists the gateway addresses to connect to. A single gateway
+	// may be listed more than once to open multiple channels against it.
+	Gateways []string
+
+	// Layer is the tunnelling layer requested for every channel.
+	Layer TunnelLayer
+
+	// Tunnel configures each individual channel.
+	Tunnel TunnelConfig
+
+	// Strategy selects how outgoing requests are distributed across
+	// channels. It defaults to RoundRobin.
+	Strategy PoolStrategy
+
+	// DedupWindow is how long a frame's identity is remembered in order to
+	// drop duplicates observed on more than one channel. It defaults to one
+	// second.
+	DedupWindow time.Duration
+}
+
+// PoolMetrics is a snapshot of a TunnelPool's health.
+type PoolMetrics struct {
+	// OpenChannels is the number of channels currently connected.
+	OpenChannels int
+
+	// Errors counts connection and heartbeat errors observed per gateway.
+	Errors map[string]uint64
+
+	// LastStatus is the most recent ConnStateRes status observed per
+	// gateway.
+	LastStatus map[string]proto.ConnState
+}
+
+// A TunnelPool manages several tunnel channels, possibly against different
+// gateways, and transparently retries outgoing requests on another channel
+// when one reports a connection error. It merges the channels' inbound
+// events into a single, deduplicated stream.
+type TunnelPool struct {
+	mu       sync.Mutex
+	channels []*pooledChannel
+	cursor   int
+	strategy PoolStrategy
+
+	inbound chan cemi.Message
+	closed  chan struct{}
+
+	dedupWindow time.Duration
+	seenMu      sync.Mutex
+	seen        map[string]time.Time
+}
+
+// pooledChannel tracks one tunnel channel and its health within a pool.
+type pooledChannel struct {
+	gateway string
+	tunnel  *Tunnel
+
+	mu         sync.Mutex
+	inFlight   int
+	errors     uint64
+	lastStatus proto.ConnState
+	available  bool
+}
+
+// poolChannelObserver forwards a channel's lifecycle events into its
+// pooledChannel's health fields, so the pool can make real failover and
+// metrics decisions instead of only reacting to Send errors.
+type poolChannelObserver struct {
+	channel *pooledChannel
+}
+
+// OnConnect implements Observer.
+func (o *poolChannelObserver) OnConnect(_ string, _ TunnelLayer, status proto.ConnResStatus, _ time.Duration) {
+	o.channel.mu.Lock()
+	defer o.channel.mu.Unlock()
+
+	if status != proto.ConnResOk {
+		o.channel.errors++
+		o.channel.available = false
+	}
+}
+
+// OnHeartbeat implements Observer by recording the channel's connection
+// state and marking it unavailable once a heartbeat fails or reports
+// anything other than ConnStateNormal, so channelOrder stops routing new
+// requests to it until it recovers. lastStatus is only updated from a
+// heartbeat that actually completed; a failed or timed-out exchange carries
+// no real ConnStateRes, so it only affects availability, not the reported
+// last-known status.
+func (o *poolChannelObserver) OnHeartbeat(_ string, _ uint8, state proto.ConnState, _ time.Duration, err error) {
+	o.channel.mu.Lock()
+	defer o.channel.mu.Unlock()
+
+	if err != nil {
+		o.channel.errors++
+		o.channel.available = false
+		return
+	}
+
+	o.channel.lastStatus = state
+
+	if state != proto.ConnStateNormal {
+		o.channel.errors++
+		o.channel.available = false
+	} else {
+		o.channel.available = true
+	}
+}
+
+// OnDisconnect implements Observer.
+func (o *poolChannelObserver) OnDisconnect(_ string, _ uint8, _ uint8) {
+	o.channel.mu.Lock()
+	defer o.channel.mu.Unlock()
+
+	o.channel.available = false
+}
+
+// OnSessionEnd implements Observer.
+func (o *poolChannelObserver) OnSessionEnd(string, TunnelLayer, time.Duration) {}
+
+// combineObservers returns an Observer that notifies both primary and, if
+// set, extra. It is used so a pool's own health tracking doesn't displace an
+// Observer the caller configured for metrics or tracing.
+func combineObservers(primary Observer, extra Observer) Observer {
+	if extra == nil {
+		return primary
+	}
+
+	return &multiObserver{observers: []Observer{primary, extra}}
+}
+
+// multiObserver forwards every event to each of its observers in order.
+type multiObserver struct {
+	observers []Observer
+}
+
+// OnConnect implements Observer.
+func (m *multiObserver) OnConnect(gateway string, layer TunnelLayer, status proto.ConnResStatus, elapsed time.Duration) {
+	for _, o := range m.observers {
+		o.OnConnect(gateway, layer, status, elapsed)
+	}
+}
+
+// OnHeartbeat implements Observer.
+func (m *multiObserver) OnHeartbeat(gateway string, channel uint8, state proto.ConnState, rtt time.Duration, err error) {
+	for _, o := range m.observers {
+		o.OnHeartbeat(gateway, channel, state, rtt, err)
+	}
+}
+
+// OnDisconnect implements Observer.
+func (m *multiObserver) OnDisconnect(gateway string, channel uint8, status uint8) {
+	for _, o := range m.observers {
+		o.OnDisconnect(gateway, channel, status)
+	}
+}
+
+// OnSessionEnd implements Observer.
+func (m *multiObserver) OnSessionEnd(gateway string, layer TunnelLayer, duration time.Duration) {
+	for _, o := range m.observers {
+		o.OnSessionEnd(gateway, layer, duration)
+	}
+}
+
+// NewTunnelPool dials a channel for every gateway in config.Gateways and
+// returns a pool that fans their inbound events into one stream.
+func NewTunnelPool(config PoolConfig) (*TunnelPool, error) {
+	if len(config.Gateways) == 0 {
+		return nil, errors.New("knx: tunnel pool requires at least one gateway")
+	}
+
+	dedupWindow := config.DedupWindow
+	if dedupWindow <= 0 {
+		dedupWindow = time.Second
+	}
+
+	pool := &TunnelPool{
+		strategy:    config.Strategy,
+		inbound:     make(chan cemi.Message),
+		closed:      make(chan struct{}),
+		dedupWindow: dedupWindow,
+		seen:        make(map[string]time.Time),
+	}
+
+	for _, gateway := range config.Gateways {
+		channel := &pooledChannel{gateway: gateway, lastStatus: proto.ConnStateNormal}
+
+		tunnelConfig := config.Tunnel
+		tunnelConfig.Observer = combineObservers(&poolChannelObserver{channel: channel}, config.Tunnel.Observer)
+
+		tunnel, err := NewTunnel(gateway, config.Layer, tunnelConfig)
+		if err != nil {
+			pool.Close()
+			return nil, fmt.Errorf("knx: connecting to %s: %w", gateway, err)
+		}
+
+		channel.mu.Lock()
+		channel.tunnel = tunnel
+		channel.available = true
+		channel.mu.Unlock()
+
+		pool.channels = append(pool.channels, channel)
+
+		go pool.fanIn(channel)
+	}
+
+	return pool, nil
+}
+
+// fanIn forwards a channel's inbound events into the pool's merged stream,
+// dropping frames that were already observed on another channel.
+func (pool *TunnelPool) fanIn(channel *pooledChannel) {
+	for msg := range channel.tunnel.Inbound() {
+		if pool.isDuplicate(msg) {
+			continue
+		}
+
+		select {
+		case pool.inbound <- msg:
+		case <-pool.closed:
+			return
+		}
+	}
+}
+
+// isDuplicate reports whether an equivalent frame was already forwarded
+// within the pool's dedup window, and records this one if not.
+func (pool *TunnelPool) isDuplicate(msg cemi.Message) bool {
+	key := frameKey(msg)
+
+	pool.seenMu.Lock()
+	defer pool.seenMu.Unlock()
+
+	now := time.Now()
+	for k, seenAt := range pool.seen {
+		if now.Sub(seenAt) > pool.dedupWindow {
+			delete(pool.seen, k)
+		}
+	}
+
+	if _, ok := pool.seen[key]; ok {
+		return true
+	}
+
+	pool.seen[key] = now
+
+	return false
+}
+
+// frameKey derives a stable identity for a cEMI message used for
+// deduplication across channels.
+func frameKey(msg cemi.Message) string {
+	var buf []byte
+
+	// cemi.Message implementations serialize deterministically, so their
+	// wire representation is a suitable, if coarse, deduplication key.
+	w := &byteSink{buf: &buf}
+	msg.WriteTo(w)
+
+	return string(buf)
+}
+
+// byteSink is a minimal io.Writer that appends to a byte slice, used to
+// render a message for hashing without pulling in bytes.Buffer just for
+// this.
+type byteSink struct {
+	buf *[]byte
+}
+
+func (s *byteSink) Write(p []byte) (int, error) {
+	*s.buf = append(*s.buf, p...)
+	return len(p), nil
+}
+
+// Inbound returns the channel on which deduplicated events from every
+// channel in the pool are delivered.
+func (pool *TunnelPool) Inbound() <-chan cemi.Message {
+	return pool.inbound
+}
+
+// Metrics returns a snapshot of the pool's channel health.
+func (pool *TunnelPool) Metrics() PoolMetrics {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	metrics := PoolMetrics{
+		Errors:     make(map[string]uint64, len(pool.channels)),
+		LastStatus: make(map[string]proto.ConnState, len(pool.channels)),
+	}
+
+	for _, channel := range pool.channels {
+		channel.mu.Lock()
+		metrics.Errors[channel.gateway] += channel.errors
+		metrics.LastStatus[channel.gateway] = channel.lastStatus
+		if channel.available {
+			metrics.OpenChannels++
+		}
+		channel.mu.Unlock()
+	}
+
+	return metrics
+}
+
+// Send submits a cEMI message on a channel chosen according to the pool's
+// strategy, retrying on the remaining channels if the chosen one reports a
+// connection error.
+func (pool *TunnelPool) Send(msg cemi.Message) error {
+	pool.mu.Lock()
+	order := pool.channelOrder()
+	pool.mu.Unlock()
+
+	if len(order) == 0 {
+		return errors.New("knx: tunnel pool has no channels")
+	}
+
+	var lastErr error
+
+	for _, channel := range order {
+		channel.mu.Lock()
+		channel.inFlight++
+		channel.mu.Unlock()
+
+		err := channel.tunnel.Send(msg)
+
+		channel.mu.Lock()
+		channel.inFlight--
+		if err != nil {
+			channel.errors++
+		}
+		channel.mu.Unlock()
+
+		if err == nil {
+			return nil
+		}
+
+		lastErr = err
+	}
+
+	return fmt.Errorf("knx: all channels failed, last error: %w", lastErr)
+}
+
+// channelOrder returns the pool's channels ordered according to its
+// configured strategy, starting with the channel a request should prefer.
+// Channels whose last heartbeat reported a connection error are moved to
+// the back so that heartbeat-detected failures are avoided just as
+// Send-detected ones are, not only reacted to after a request already
+// failed on them. If every channel is unavailable, all are still returned
+// so a request can be attempted rather than rejected outright.
+func (pool *TunnelPool) channelOrder() []*pooledChannel {
+	channels := append([]*pooledChannel(nil), pool.channels...)
+	if len(channels) == 0 {
+		return channels
+	}
+
+	switch pool.strategy {
+	case LeastLoaded:
+		sortByLoad(channels)
+
+	default: // RoundRobin
+		pool.cursor = (pool.cursor + 1) % len(channels)
+		channels = append(channels[pool.cursor:], channels[:pool.cursor]...)
+	}
+
+	return partitionByAvailability(channels)
+}
+
+// partitionByAvailability moves channels known to be unhealthy to the back
+// of the slice, preserving the relative order within each group.
+func partitionByAvailability(channels []*pooledChannel) []*pooledChannel {
+	ordered := make([]*pooledChannel, 0, len(channels))
+	var unavailable []*pooledChannel
+
+	for _, channel := range channels {
+		channel.mu.Lock()
+		available := channel.available
+		channel.mu.Unlock()
+
+		if available {
+			ordered = append(ordered, channel)
+		} else {
+			unavailable = append(unavailable, channel)
+		}
+	}
+
+	return append(ordered, unavailable...)
+}
+
+// sortByLoad orders channels by ascending in-flight request count using a
+// simple insertion sort; pools are expected to hold a handful of channels.
+// inFlight is mutated by Send under channel.mu from other goroutines, so
+// each channel's load is snapshotted under its lock before any comparison,
+// rather than read directly during the sort.
+func sortByLoad(channels []*pooledChannel) {
+	type weightedChannel struct {
+		channel *pooledChannel
+		load    int
+	}
+
+	weighted := make([]weightedChannel, len(channels))
+	for i, channel := range channels {
+		channel.mu.Lock()
+		weighted[i] = weightedChannel{channel: channel, load: channel.inFlight}
+		channel.mu.Unlock()
+	}
+
+	for i := 1; i < len(weighted); i++ {
+		for j := i; j > 0 && weighted[j].load < weighted[j-1].load; j-- {
+			weighted[j], weighted[j-1] = weighted[j-1], weighted[j]
+		}
+	}
+
+	for i, w := range weighted {
+		channels[i] = w.channel
+	}
+}
+
+// Close shuts every channel in the pool down.
+func (pool *TunnelPool) Close() {
+	select {
+	case <-pool.closed:
+		return
+	default:
+		close(pool.closed)
+	}
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	for _, channel := range pool.channels {
+		channel.tunnel.Close()
+	}
+}
@@ -0,0 +1,95 @@
+package knx
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vapourismo/knx-go/knx/proto"
+)
+
+// recordingObserver is a minimal Observer used to assert which callbacks were
+// invoked, without pulling in a real tunnel connection.
+type recordingObserver struct {
+	onConnect func()
+}
+
+func (o *recordingObserver) OnConnect(string, TunnelLayer, proto.ConnResStatus, time.Duration) {
+	if o.onConnect != nil {
+		o.onConnect()
+	}
+}
+
+func (o *recordingObserver) OnHeartbeat(string, uint8, proto.ConnState, time.Duration, error) {}
+
+func (o *recordingObserver) OnDisconnect(string, uint8, uint8) {}
+
+func (o *recordingObserver) OnSessionEnd(string, TunnelLayer, time.Duration) {}
+
+func TestSortByLoad(t *testing.T) {
+	channels := []*pooledChannel{
+		{gateway: "a", inFlight: 3},
+		{gateway: "b", inFlight: 1},
+		{gateway: "c", inFlight: 2},
+	}
+
+	sortByLoad(channels)
+
+	want := []string{"b", "c", "a"}
+	for i, gw := range want {
+		if channels[i].gateway != gw {
+			t.Fatalf("channels[%d].gateway = %q, want %q", i, channels[i].gateway, gw)
+		}
+	}
+}
+
+func TestPartitionByAvailabilityKeepsAvailableFirst(t *testing.T) {
+	down := &pooledChannel{gateway: "down", available: false}
+	up1 := &pooledChannel{gateway: "up1", available: true}
+	up2 := &pooledChannel{gateway: "up2", available: true}
+
+	ordered := partitionByAvailability([]*pooledChannel{down, up1, up2})
+
+	want := []*pooledChannel{up1, up2, down}
+	if len(ordered) != len(want) {
+		t.Fatalf("len(ordered) = %d, want %d", len(ordered), len(want))
+	}
+
+	for i := range want {
+		if ordered[i] != want[i] {
+			t.Fatalf("ordered[%d] = %q, want %q", i, ordered[i].gateway, want[i].gateway)
+		}
+	}
+}
+
+func TestPartitionByAvailabilityReturnsAllWhenNoneAvailable(t *testing.T) {
+	a := &pooledChannel{gateway: "a", available: false}
+	b := &pooledChannel{gateway: "b", available: false}
+
+	ordered := partitionByAvailability([]*pooledChannel{a, b})
+
+	if len(ordered) != 2 {
+		t.Fatalf("len(ordered) = %d, want 2", len(ordered))
+	}
+}
+
+func TestCombineObserversNotifiesBoth(t *testing.T) {
+	var primaryCalled, extraCalled bool
+
+	primary := &recordingObserver{onConnect: func() { primaryCalled = true }}
+	extra := &recordingObserver{onConnect: func() { extraCalled = true }}
+
+	combined := combineObservers(primary, extra)
+	combined.OnConnect("gw", TunnelLayerData, 0, 0)
+
+	if !primaryCalled || !extraCalled {
+		t.Fatalf("primaryCalled=%v extraCalled=%v, want both true", primaryCalled, extraCalled)
+	}
+}
+
+func TestCombineObserversWithNilExtraReturnsPrimary(t *testing.T) {
+	primary := &recordingObserver{}
+
+	if combined := combineObservers(primary, nil); combined != primary {
+		t.Fatal("combineObservers with a nil extra should return primary unchanged")
+	}
+}
@@ -0,0 +1,297 @@
+package proto
+
+import (
+	"errors"
+	"io"
+
+	"github.com/vapourismo/knx-go/knx/encoding"
+	"github.com/vapourismo/knx-go/knx/util"
+)
+
+// These are the service identifiers used by KNXnet/IP discovery.
+const (
+	SearchReqService      ServiceID = 0x0201
+	SearchResService      ServiceID = 0x0202
+	DescriptionReqService ServiceID = 0x0203
+	DescriptionResService ServiceID = 0x0204
+)
+
+// These are the known Description Information Block (DIB) type codes.
+const (
+	dibDeviceInfo      uint8 = 0x01
+	dibSuppSvcFamilies uint8 = 0x02
+	dibIPConfig        uint8 = 0x03
+)
+
+// A DeviceInfoDIB describes a gateway's identity: its KNX individual
+// address, serial number, routing multicast address, MAC address and
+// friendly name.
+type DeviceInfoDIB struct {
+	KNXMedium             uint8
+	DeviceStatus          uint8
+	IndividualAddress     uint16
+	ProjectInstallationID uint16
+	SerialNumber          [6]byte
+	MulticastAddress      [4]byte
+	MAC                   [6]byte
+	FriendlyName          [30]byte
+}
+
+// Unpack initializes the structure by parsing the given Device Info DIB,
+// including its 2-byte length/type header.
+func (dib *DeviceInfoDIB) Unpack(data []byte) (uint, error) {
+	var length, typeCode uint8
+
+	n, err := util.UnpackSome(
+		data, &length, &typeCode, &dib.KNXMedium, &dib.DeviceStatus, &dib.IndividualAddress,
+		&dib.ProjectInstallationID, &dib.SerialNumber, &dib.MulticastAddress, &dib.MAC, &dib.FriendlyName,
+	)
+	if err != nil {
+		return n, err
+	}
+
+	if typeCode != dibDeviceInfo {
+		return n, errors.New("proto: not a Device Info DIB")
+	}
+
+	return n, nil
+}
+
+// WriteTo serializes the structure and writes it to the given Writer,
+// including its 2-byte length/type header.
+func (dib *DeviceInfoDIB) WriteTo(w io.Writer) (int64, error) {
+	return encoding.WriteSome(
+		w, uint8(54), dibDeviceInfo, dib.KNXMedium, dib.DeviceStatus, dib.IndividualAddress,
+		dib.ProjectInstallationID, dib.SerialNumber[:], dib.MulticastAddress[:], dib.MAC[:], dib.FriendlyName[:],
+	)
+}
+
+// A ServiceFamily advertises support for a KNXnet/IP service family at a
+// given version, as carried in a SuppSvcFamiliesDIB.
+type ServiceFamily struct {
+	ID      uint8
+	Version uint8
+}
+
+// A SuppSvcFamiliesDIB lists the KNXnet/IP service families a gateway
+// supports, e.g. Core, Device Management, Tunnelling, or Routing, and the
+// version of each.
+type SuppSvcFamiliesDIB struct {
+	Families []ServiceFamily
+}
+
+// Unpack initializes the structure by parsing the given Supported Service
+// Families DIB, including its 2-byte length/type header.
+func (dib *SuppSvcFamiliesDIB) Unpack(data []byte) (uint, error) {
+	var length, typeCode uint8
+
+	n, err := util.UnpackSome(data, &length, &typeCode)
+	if err != nil {
+		return n, err
+	}
+
+	if typeCode != dibSuppSvcFamilies {
+		return n, errors.New("proto: not a Supported Service Families DIB")
+	}
+
+	if int(length) > len(data) {
+		return n, errors.New("proto: Supported Service Families DIB exceeds given data")
+	}
+
+	dib.Families = dib.Families[:0]
+
+	for i := uint(n); i+1 < uint(length); i += 2 {
+		dib.Families = append(dib.Families, ServiceFamily{ID: data[i], Version: data[i+1]})
+	}
+
+	return uint(length), nil
+}
+
+// WriteTo serializes the structure and writes it to the given Writer,
+// including its 2-byte length/type header.
+func (dib *SuppSvcFamiliesDIB) WriteTo(w io.Writer) (int64, error) {
+	length := uint8(2 + 2*len(dib.Families))
+
+	args := make([]interface{}, 0, 2+len(dib.Families)*2)
+	args = append(args, length, dibSuppSvcFamilies)
+
+	for _, family := range dib.Families {
+		args = append(args, family.ID, family.Version)
+	}
+
+	return encoding.WriteSome(w, args...)
+}
+
+// An IPConfigDIB describes a gateway's current IP configuration.
+type IPConfigDIB struct {
+	IPAddress        [4]byte
+	SubnetMask       [4]byte
+	DefaultGateway   [4]byte
+	Capabilities     uint8
+	AssignmentMethod uint8
+}
+
+// Unpack initializes the structure by parsing the given IP Config DIB,
+// including its 2-byte length/type header.
+func (dib *IPConfigDIB) Unpack(data []byte) (uint, error) {
+	var length, typeCode uint8
+
+	n, err := util.UnpackSome(
+		data, &length, &typeCode, &dib.IPAddress, &dib.SubnetMask, &dib.DefaultGateway,
+		&dib.Capabilities, &dib.AssignmentMethod,
+	)
+	if err != nil {
+		return n, err
+	}
+
+	if typeCode != dibIPConfig {
+		return n, errors.New("proto: not an IP Config DIB")
+	}
+
+	return n, nil
+}
+
+// WriteTo serializes the structure and writes it to the given Writer,
+// including its 2-byte length/type header.
+func (dib *IPConfigDIB) WriteTo(w io.Writer) (int64, error) {
+	return encoding.WriteSome(
+		w, uint8(16), dibIPConfig, dib.IPAddress[:], dib.SubnetMask[:], dib.DefaultGateway[:],
+		dib.Capabilities, dib.AssignmentMethod,
+	)
+}
+
+// A SearchReq asks every gateway on the multicast group to identify itself.
+type SearchReq struct {
+	Control HostInfo
+}
+
+// Service returns the service identifier for search requests.
+func (SearchReq) Service() ServiceID {
+	return SearchReqService
+}
+
+// Unpack initializes the structure by parsing the given data.
+func (req *SearchReq) Unpack(data []byte) (uint, error) {
+	return util.UnpackSome(data, &req.Control)
+}
+
+// WriteTo serializes the structure and writes it to the given Writer.
+func (req *SearchReq) WriteTo(w io.Writer) (int64, error) {
+	return encoding.WriteSome(w, &req.Control)
+}
+
+// A SearchRes is a gateway's reply to a SearchReq, identifying itself and
+// advertising its supported service families.
+type SearchRes struct {
+	Control HostInfo
+	Device  DeviceInfoDIB
+	SuppSvc SuppSvcFamiliesDIB
+}
+
+// Service returns the service identifier for search responses.
+func (SearchRes) Service() ServiceID {
+	return SearchResService
+}
+
+// Unpack initializes the structure by parsing the given data.
+func (res *SearchRes) Unpack(data []byte) (n uint, err error) {
+	n, err = util.UnpackSome(data, &res.Control)
+	if err != nil {
+		return
+	}
+
+	m, err := res.Device.Unpack(data[n:])
+	if err != nil {
+		return n, err
+	}
+	n += m
+
+	m, err = res.SuppSvc.Unpack(data[n:])
+	if err != nil {
+		return n, err
+	}
+	n += m
+
+	return n, nil
+}
+
+// WriteTo serializes the structure and writes it to the given Writer.
+func (res *SearchRes) WriteTo(w io.Writer) (int64, error) {
+	return encoding.WriteSome(w, &res.Control, &res.Device, &res.SuppSvc)
+}
+
+// A DescriptionReq asks a specific gateway for its full self-description.
+type DescriptionReq struct {
+	Control HostInfo
+}
+
+// Service returns the service identifier for description requests.
+func (DescriptionReq) Service() ServiceID {
+	return DescriptionReqService
+}
+
+// Unpack initializes the structure by parsing the given data.
+func (req *DescriptionReq) Unpack(data []byte) (uint, error) {
+	return util.UnpackSome(data, &req.Control)
+}
+
+// WriteTo serializes the structure and writes it to the given Writer.
+func (req *DescriptionReq) WriteTo(w io.Writer) (int64, error) {
+	return encoding.WriteSome(w, &req.Control)
+}
+
+// A DescriptionRes is a gateway's reply to a DescriptionReq. Unlike
+// SearchRes, it may include the gateway's current IP configuration.
+type DescriptionRes struct {
+	Device   DeviceInfoDIB
+	SuppSvc  SuppSvcFamiliesDIB
+	IPConfig *IPConfigDIB
+}
+
+// Service returns the service identifier for description responses.
+func (DescriptionRes) Service() ServiceID {
+	return DescriptionResService
+}
+
+// Unpack initializes the structure by parsing the given data.
+func (res *DescriptionRes) Unpack(data []byte) (n uint, err error) {
+	n, err = res.Device.Unpack(data)
+	if err != nil {
+		return
+	}
+
+	m, err := res.SuppSvc.Unpack(data[n:])
+	if err != nil {
+		return n, err
+	}
+	n += m
+
+	if n < uint(len(data)) {
+		var ipConfig IPConfigDIB
+
+		m, err = ipConfig.Unpack(data[n:])
+		if err != nil {
+			return n, err
+		}
+
+		res.IPConfig = &ipConfig
+		n += m
+	}
+
+	return n, nil
+}
+
+// WriteTo serializes the structure and writes it to the given Writer.
+func (res *DescriptionRes) WriteTo(w io.Writer) (int64, error) {
+	n, err := encoding.WriteSome(w, &res.Device, &res.SuppSvc)
+	if err != nil {
+		return n, err
+	}
+
+	if res.IPConfig == nil {
+		return n, nil
+	}
+
+	m, err := res.IPConfig.WriteTo(w)
+	return n + m, err
+}
@@ -0,0 +1,87 @@
+package proto
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDeviceInfoDIBRoundTrip(t *testing.T) {
+	want := DeviceInfoDIB{
+		KNXMedium:         2,
+		IndividualAddress: 0x1101,
+		SerialNumber:      [6]byte{1, 2, 3, 4, 5, 6},
+		MulticastAddress:  [4]byte{224, 0, 23, 12},
+		MAC:               [6]byte{0xde, 0xad, 0xbe, 0xef, 0x00, 0x01},
+	}
+	copy(want.FriendlyName[:], "test gateway")
+
+	var buf bytes.Buffer
+	if _, err := want.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+
+	var got DeviceInfoDIB
+	if _, err := got.Unpack(buf.Bytes()); err != nil {
+		t.Fatalf("Unpack: %v", err)
+	}
+
+	if got != want {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestDeviceInfoDIBRejectsWrongType(t *testing.T) {
+	data := []byte{54, 0xff}
+	data = append(data, make([]byte, 52)...)
+
+	var dib DeviceInfoDIB
+	if _, err := dib.Unpack(data); err == nil {
+		t.Fatal("Unpack accepted a DIB with the wrong type code")
+	}
+}
+
+func TestSuppSvcFamiliesDIBRoundTrip(t *testing.T) {
+	want := SuppSvcFamiliesDIB{Families: []ServiceFamily{{ID: 2, Version: 1}, {ID: 4, Version: 2}}}
+
+	var buf bytes.Buffer
+	if _, err := want.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+
+	var got SuppSvcFamiliesDIB
+	if _, err := got.Unpack(buf.Bytes()); err != nil {
+		t.Fatalf("Unpack: %v", err)
+	}
+
+	if len(got.Families) != len(want.Families) {
+		t.Fatalf("len(Families) = %d, want %d", len(got.Families), len(want.Families))
+	}
+
+	for i := range want.Families {
+		if got.Families[i] != want.Families[i] {
+			t.Fatalf("Families[%d] = %+v, want %+v", i, got.Families[i], want.Families[i])
+		}
+	}
+}
+
+func TestIPConfigDIBRoundTrip(t *testing.T) {
+	want := IPConfigDIB{
+		IPAddress:      [4]byte{192, 168, 1, 10},
+		SubnetMask:     [4]byte{255, 255, 255, 0},
+		DefaultGateway: [4]byte{192, 168, 1, 1},
+	}
+
+	var buf bytes.Buffer
+	if _, err := want.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+
+	var got IPConfigDIB
+	if _, err := got.Unpack(buf.Bytes()); err != nil {
+		t.Fatalf("Unpack: %v", err)
+	}
+
+	if got != want {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,91 @@
+package proto
+
+import (
+	"io"
+
+	"github.com/vapourismo/knx-go/knx/encoding"
+	"github.com/vapourismo/knx-go/knx/util"
+)
+
+// These are the service identifiers used by KNXnet/IP Routing.
+const (
+	RoutingIndService  ServiceID = 0x0530
+	RoutingLostService ServiceID = 0x0531
+	RoutingBusyService ServiceID = 0x0532
+)
+
+// A RoutingInd carries a single cEMI frame multicast over a KNXnet/IP
+// routing connection. Unlike tunnelling, routing has no connection handshake
+// or sequence counter; every router on the multicast group receives every
+// indication.
+type RoutingInd struct {
+	// Data is the serialized cEMI frame being routed, usually an
+	// L_Data.ind.
+	Data []byte
+}
+
+// Service returns the service identifier for routing indications.
+func (RoutingInd) Service() ServiceID {
+	return RoutingIndService
+}
+
+// Unpack initializes the structure by parsing the given data.
+func (ind *RoutingInd) Unpack(data []byte) (uint, error) {
+	ind.Data = make([]byte, len(data))
+	copy(ind.Data, data)
+
+	return uint(len(data)), nil
+}
+
+// WriteTo serializes the structure and writes it to the given Writer.
+func (ind *RoutingInd) WriteTo(w io.Writer) (int64, error) {
+	return encoding.WriteSome(w, ind.Data)
+}
+
+// A RoutingLostMessage informs the multicast group that a router's queue has
+// overflowed and frames were discarded.
+type RoutingLostMessage struct {
+	Reserved         uint8
+	DeviceState      uint8
+	LostMessageCount uint16
+}
+
+// Service returns the service identifier for routing lost message
+// notifications.
+func (RoutingLostMessage) Service() ServiceID {
+	return RoutingLostService
+}
+
+// Unpack initializes the structure by parsing the given data.
+func (lost *RoutingLostMessage) Unpack(data []byte) (uint, error) {
+	return util.UnpackSome(data, &lost.Reserved, &lost.DeviceState, &lost.LostMessageCount)
+}
+
+// WriteTo serializes the structure and writes it to the given Writer.
+func (lost *RoutingLostMessage) WriteTo(w io.Writer) (int64, error) {
+	return encoding.WriteSome(w, lost.Reserved, lost.DeviceState, lost.LostMessageCount)
+}
+
+// A RoutingBusy asks every router on the multicast group to slow down
+// because the sender is overloaded.
+type RoutingBusy struct {
+	Reserved     uint8
+	DeviceState  uint8
+	WaitTime     uint16
+	ControlField uint16
+}
+
+// Service returns the service identifier for routing busy notifications.
+func (RoutingBusy) Service() ServiceID {
+	return RoutingBusyService
+}
+
+// Unpack initializes the structure by parsing the given data.
+func (busy *RoutingBusy) Unpack(data []byte) (uint, error) {
+	return util.UnpackSome(data, &busy.Reserved, &busy.DeviceState, &busy.WaitTime, &busy.ControlField)
+}
+
+// WriteTo serializes the structure and writes it to the given Writer.
+func (busy *RoutingBusy) WriteTo(w io.Writer) (int64, error) {
+	return encoding.WriteSome(w, busy.Reserved, busy.DeviceState, busy.WaitTime, busy.ControlField)
+}
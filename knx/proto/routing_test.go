@@ -0,0 +1,60 @@
+package proto
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRoutingIndRoundTrip(t *testing.T) {
+	want := RoutingInd{Data: []byte{0x29, 0x00, 0xbc, 0xe0, 0x11, 0x00, 0x08, 0x01, 0x00, 0x81}}
+
+	var buf bytes.Buffer
+	if _, err := want.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+
+	var got RoutingInd
+	if _, err := got.Unpack(buf.Bytes()); err != nil {
+		t.Fatalf("Unpack: %v", err)
+	}
+
+	if !bytes.Equal(got.Data, want.Data) {
+		t.Fatalf("Data = %x, want %x", got.Data, want.Data)
+	}
+}
+
+func TestRoutingLostMessageRoundTrip(t *testing.T) {
+	want := RoutingLostMessage{DeviceState: 1, LostMessageCount: 3}
+
+	var buf bytes.Buffer
+	if _, err := want.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+
+	var got RoutingLostMessage
+	if _, err := got.Unpack(buf.Bytes()); err != nil {
+		t.Fatalf("Unpack: %v", err)
+	}
+
+	if got.DeviceState != want.DeviceState || got.LostMessageCount != want.LostMessageCount {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestRoutingBusyRoundTrip(t *testing.T) {
+	want := RoutingBusy{DeviceState: 2, WaitTime: 100, ControlField: 0}
+
+	var buf bytes.Buffer
+	if _, err := want.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+
+	var got RoutingBusy
+	if _, err := got.Unpack(buf.Bytes()); err != nil {
+		t.Fatalf("Unpack: %v", err)
+	}
+
+	if got.DeviceState != want.DeviceState || got.WaitTime != want.WaitTime {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,241 @@
+package proto
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/vapourismo/knx-go/knx/encoding"
+	"github.com/vapourismo/knx-go/knx/util"
+)
+
+// These are the service identifiers used by KNXnet/IP Secure.
+const (
+	SecureWrapperService ServiceID = 0x0950
+	SessionReqService    ServiceID = 0x0951
+	SessionResService    ServiceID = 0x0952
+	SessionAuthService   ServiceID = 0x0953
+	SessionStatusService ServiceID = 0x0954
+	TimerNotifyService   ServiceID = 0x0955
+)
+
+// A SessionReq requests a new secure session from a KNXnet/IP Secure server.
+type SessionReq struct {
+	Control   HostInfo
+	PublicKey [32]byte
+}
+
+// Service returns the service identifier for secure session requests.
+func (SessionReq) Service() ServiceID {
+	return SessionReqService
+}
+
+// Unpack initializes the structure by parsing the given data.
+func (req *SessionReq) Unpack(data []byte) (uint, error) {
+	return util.UnpackSome(data, &req.Control, &req.PublicKey)
+}
+
+// WriteTo serializes the structure and writes it to the given Writer.
+func (req *SessionReq) WriteTo(w io.Writer) (int64, error) {
+	return encoding.WriteSome(w, &req.Control, req.PublicKey[:])
+}
+
+// A SessionRes is the server's reply to a SessionReq. It carries the server's
+// ephemeral public key and a MAC authenticating both keys.
+type SessionRes struct {
+	ChannelID uint16
+	PublicKey [32]byte
+	MAC       [16]byte
+}
+
+// Service returns the service identifier for secure session responses.
+func (SessionRes) Service() ServiceID {
+	return SessionResService
+}
+
+// Unpack initializes the structure by parsing the given data.
+func (res *SessionRes) Unpack(data []byte) (uint, error) {
+	return util.UnpackSome(data, &res.ChannelID, &res.PublicKey, &res.MAC)
+}
+
+// WriteTo serializes the structure and writes it to the given Writer.
+func (res *SessionRes) WriteTo(w io.Writer) (int64, error) {
+	return encoding.WriteSome(w, res.ChannelID, res.PublicKey[:], res.MAC[:])
+}
+
+// A SessionAuthenticate proves knowledge of the user password to the server
+// and selects the user ID the session authenticates as.
+type SessionAuthenticate struct {
+	Reserved uint8
+	UserID   uint8
+	MAC      [16]byte
+}
+
+// Service returns the service identifier for secure session authentication.
+func (SessionAuthenticate) Service() ServiceID {
+	return SessionAuthService
+}
+
+// Unpack initializes the structure by parsing the given data.
+func (auth *SessionAuthenticate) Unpack(data []byte) (uint, error) {
+	return util.UnpackSome(data, &auth.Reserved, &auth.UserID, &auth.MAC)
+}
+
+// WriteTo serializes the structure and writes it to the given Writer.
+func (auth *SessionAuthenticate) WriteTo(w io.Writer) (int64, error) {
+	return encoding.WriteSome(w, auth.Reserved, auth.UserID, auth.MAC[:])
+}
+
+// SessionStatusCode describes the outcome of a secure session handshake.
+type SessionStatusCode uint8
+
+// These are the known session status codes.
+const (
+	SessionStatusAuthSuccess     SessionStatusCode = 0x00
+	SessionStatusAuthFailed      SessionStatusCode = 0x01
+	SessionStatusUnauthenticated SessionStatusCode = 0x02
+	SessionStatusTimeout         SessionStatusCode = 0x03
+	SessionStatusKeepalive       SessionStatusCode = 0x04
+	SessionStatusClose           SessionStatusCode = 0x05
+)
+
+// String describes the session status code.
+func (status SessionStatusCode) String() string {
+	switch status {
+	case SessionStatusAuthSuccess:
+		return "Authentication succeeded"
+
+	case SessionStatusAuthFailed:
+		return "Authentication failed"
+
+	case SessionStatusUnauthenticated:
+		return "Session is unauthenticated"
+
+	case SessionStatusTimeout:
+		return "Session timed out"
+
+	case SessionStatusKeepalive:
+		return "Session keepalive"
+
+	case SessionStatusClose:
+		return "Session closed"
+
+	default:
+		return fmt.Sprintf("Unknown session status code %#x", uint8(status))
+	}
+}
+
+// Error implements the error Error method.
+func (status SessionStatusCode) Error() string {
+	return status.String()
+}
+
+// A SessionStatus informs a peer about the state of a secure session.
+type SessionStatus struct {
+	Status SessionStatusCode
+}
+
+// Service returns the service identifier for secure session status messages.
+func (SessionStatus) Service() ServiceID {
+	return SessionStatusService
+}
+
+// Unpack initializes the structure by parsing the given data.
+func (status *SessionStatus) Unpack(data []byte) (uint, error) {
+	return util.UnpackSome(data, (*uint8)(&status.Status))
+}
+
+// WriteTo serializes the structure and writes it to the given Writer.
+func (status *SessionStatus) WriteTo(w io.Writer) (int64, error) {
+	return encoding.WriteSome(w, uint8(status.Status))
+}
+
+// A SecureWrapper wraps an arbitrary KNXnet/IP frame in an encrypted,
+// authenticated envelope as defined by KNXnet/IP Secure.
+type SecureWrapper struct {
+	ChannelID uint16
+
+	// SeqNumber is the 6-byte secure sequence information.
+	SeqNumber [6]byte
+
+	// SerialNumber is the 6-byte sender serial number (device KNX serial).
+	SerialNumber [6]byte
+
+	// MessageTag is a 2-byte value distinguishing unicast from multicast use.
+	MessageTag [2]byte
+
+	// EncryptedData is the AES-128-CCM encrypted payload, i.e. the wrapped
+	// KNXnet/IP frame followed by its 16-byte authentication tag.
+	EncryptedData []byte
+}
+
+// Service returns the service identifier for secure wrapper frames.
+func (SecureWrapper) Service() ServiceID {
+	return SecureWrapperService
+}
+
+// Unpack initializes the structure by parsing the given data.
+func (wrapper *SecureWrapper) Unpack(data []byte) (n uint, err error) {
+	n, err = util.UnpackSome(
+		data, &wrapper.ChannelID, &wrapper.SeqNumber, &wrapper.SerialNumber, &wrapper.MessageTag,
+	)
+	if err != nil {
+		return
+	}
+
+	if int(n) > len(data) {
+		return n, errors.New("secure wrapper header exceeds given data")
+	}
+
+	wrapper.EncryptedData = make([]byte, len(data)-int(n))
+	copy(wrapper.EncryptedData, data[n:])
+
+	return uint(len(data)), nil
+}
+
+// WriteTo serializes the structure and writes it to the given Writer.
+func (wrapper *SecureWrapper) WriteTo(w io.Writer) (int64, error) {
+	return encoding.WriteSome(
+		w,
+		wrapper.ChannelID,
+		wrapper.SeqNumber[:],
+		wrapper.SerialNumber[:],
+		wrapper.MessageTag[:],
+		wrapper.EncryptedData,
+	)
+}
+
+// A TimerNotify synchronizes the secure multicast timer between routers so
+// that receivers can reject replayed ROUTING_INDICATION frames.
+type TimerNotify struct {
+	// SeqNumber is the 6-byte secure sequence information carrying the timer value.
+	SeqNumber [6]byte
+
+	// SerialNumber is the 6-byte sender serial number.
+	SerialNumber [6]byte
+
+	// MessageTag is a 2-byte value distinguishing unicast from multicast use.
+	MessageTag [2]byte
+
+	// MAC authenticates the timer value.
+	MAC [16]byte
+}
+
+// Service returns the service identifier for timer notify frames.
+func (TimerNotify) Service() ServiceID {
+	return TimerNotifyService
+}
+
+// Unpack initializes the structure by parsing the given data.
+func (notify *TimerNotify) Unpack(data []byte) (uint, error) {
+	return util.UnpackSome(
+		data, &notify.SeqNumber, &notify.SerialNumber, &notify.MessageTag, &notify.MAC,
+	)
+}
+
+// WriteTo serializes the structure and writes it to the given Writer.
+func (notify *TimerNotify) WriteTo(w io.Writer) (int64, error) {
+	return encoding.WriteSome(
+		w, notify.SeqNumber[:], notify.SerialNumber[:], notify.MessageTag[:], notify.MAC[:],
+	)
+}
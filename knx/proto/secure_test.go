@@ -0,0 +1,131 @@
+package proto
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSessionReqRoundTrip(t *testing.T) {
+	want := SessionReq{PublicKey: [32]byte{1, 2, 3}}
+
+	var buf bytes.Buffer
+	if _, err := want.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+
+	var got SessionReq
+	if _, err := got.Unpack(buf.Bytes()); err != nil {
+		t.Fatalf("Unpack: %v", err)
+	}
+
+	if got.PublicKey != want.PublicKey {
+		t.Fatalf("PublicKey = %x, want %x", got.PublicKey, want.PublicKey)
+	}
+}
+
+func TestSessionResRoundTrip(t *testing.T) {
+	want := SessionRes{
+		ChannelID: 7,
+		PublicKey: [32]byte{4, 5, 6},
+		MAC:       [16]byte{7, 8, 9},
+	}
+
+	var buf bytes.Buffer
+	if _, err := want.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+
+	var got SessionRes
+	if _, err := got.Unpack(buf.Bytes()); err != nil {
+		t.Fatalf("Unpack: %v", err)
+	}
+
+	if got != want {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestSessionAuthenticateRoundTrip(t *testing.T) {
+	want := SessionAuthenticate{UserID: 3, MAC: [16]byte{9, 9, 9}}
+
+	var buf bytes.Buffer
+	if _, err := want.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+
+	var got SessionAuthenticate
+	if _, err := got.Unpack(buf.Bytes()); err != nil {
+		t.Fatalf("Unpack: %v", err)
+	}
+
+	if got.UserID != want.UserID || got.MAC != want.MAC {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestSessionStatusRoundTrip(t *testing.T) {
+	want := SessionStatus{Status: SessionStatusAuthFailed}
+
+	var buf bytes.Buffer
+	if _, err := want.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+
+	var got SessionStatus
+	if _, err := got.Unpack(buf.Bytes()); err != nil {
+		t.Fatalf("Unpack: %v", err)
+	}
+
+	if got.Status != want.Status {
+		t.Fatalf("Status = %v, want %v", got.Status, want.Status)
+	}
+}
+
+func TestSecureWrapperRoundTrip(t *testing.T) {
+	want := SecureWrapper{
+		ChannelID:     42,
+		SeqNumber:     [6]byte{0, 0, 0, 0, 0, 1},
+		SerialNumber:  [6]byte{1, 2, 3, 4, 5, 6},
+		MessageTag:    [2]byte{0xaa, 0xbb},
+		EncryptedData: []byte("encrypted-payload-and-mac"),
+	}
+
+	var buf bytes.Buffer
+	if _, err := want.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+
+	var got SecureWrapper
+	if _, err := got.Unpack(buf.Bytes()); err != nil {
+		t.Fatalf("Unpack: %v", err)
+	}
+
+	if got.ChannelID != want.ChannelID || got.SeqNumber != want.SeqNumber ||
+		got.SerialNumber != want.SerialNumber || got.MessageTag != want.MessageTag ||
+		!bytes.Equal(got.EncryptedData, want.EncryptedData) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestTimerNotifyRoundTrip(t *testing.T) {
+	want := TimerNotify{
+		SeqNumber:    [6]byte{0, 0, 0, 0, 0, 5},
+		SerialNumber: [6]byte{6, 5, 4, 3, 2, 1},
+		MessageTag:   [2]byte{0x11, 0x22},
+		MAC:          [16]byte{1, 1, 1},
+	}
+
+	var buf bytes.Buffer
+	if _, err := want.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+
+	var got TimerNotify
+	if _, err := got.Unpack(buf.Bytes()); err != nil {
+		t.Fatalf("Unpack: %v", err)
+	}
+
+	if got != want {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
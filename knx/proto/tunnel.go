@@ -0,0 +1,79 @@
+package proto
+
+import (
+	"errors"
+	"io"
+
+	"github.com/vapourismo/knx-go/knx/encoding"
+	"github.com/vapourismo/knx-go/knx/util"
+)
+
+// These are the service identifiers used to exchange cEMI frames over an
+// established tunnelling connection.
+const (
+	TunnelReqService ServiceID = 0x0420
+	TunnelAckService ServiceID = 0x0421
+)
+
+// A TunnelReq carries a single cEMI frame over an established tunnelling
+// connection, tagged with the channel and sequence number it belongs to.
+type TunnelReq struct {
+	Channel   uint8
+	SeqNumber uint8
+
+	// Data is the serialized cEMI frame being transported.
+	Data []byte
+}
+
+// Service returns the service identifier for tunnelling requests.
+func (TunnelReq) Service() ServiceID {
+	return TunnelReqService
+}
+
+// Unpack initializes the structure by parsing the given data.
+func (req *TunnelReq) Unpack(data []byte) (n uint, err error) {
+	var length, reserved uint8
+
+	n, err = util.UnpackSome(data, &length, &req.Channel, &req.SeqNumber, &reserved)
+	if err != nil {
+		return
+	}
+
+	if length != 4 {
+		return n, errors.New("proto: invalid tunnelling request connection header length")
+	}
+
+	req.Data = make([]byte, len(data)-int(n))
+	copy(req.Data, data[n:])
+
+	return uint(len(data)), nil
+}
+
+// WriteTo serializes the structure and writes it to the given Writer.
+func (req *TunnelReq) WriteTo(w io.Writer) (int64, error) {
+	return encoding.WriteSome(w, uint8(4), req.Channel, req.SeqNumber, uint8(0), req.Data)
+}
+
+// A TunnelAck acknowledges receipt of a TunnelReq with the same channel and
+// sequence number.
+type TunnelAck struct {
+	Channel   uint8
+	SeqNumber uint8
+	Status    uint8
+}
+
+// Service returns the service identifier for tunnelling acknowledgements.
+func (TunnelAck) Service() ServiceID {
+	return TunnelAckService
+}
+
+// Unpack initializes the structure by parsing the given data.
+func (ack *TunnelAck) Unpack(data []byte) (uint, error) {
+	var length uint8
+	return util.UnpackSome(data, &length, &ack.Channel, &ack.SeqNumber, &ack.Status)
+}
+
+// WriteTo serializes the structure and writes it to the given Writer.
+func (ack *TunnelAck) WriteTo(w io.Writer) (int64, error) {
+	return encoding.WriteSome(w, uint8(4), ack.Channel, ack.SeqNumber, ack.Status)
+}
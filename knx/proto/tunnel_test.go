@@ -0,0 +1,51 @@
+package proto
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTunnelReqRoundTrip(t *testing.T) {
+	want := TunnelReq{Channel: 3, SeqNumber: 7, Data: []byte{0x11, 0x00, 0xbc, 0xe0}}
+
+	var buf bytes.Buffer
+	if _, err := want.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+
+	var got TunnelReq
+	if _, err := got.Unpack(buf.Bytes()); err != nil {
+		t.Fatalf("Unpack: %v", err)
+	}
+
+	if got.Channel != want.Channel || got.SeqNumber != want.SeqNumber || !bytes.Equal(got.Data, want.Data) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestTunnelReqUnpackRejectsBadLength(t *testing.T) {
+	data := []byte{5, 1, 2, 0, 0xaa}
+
+	var req TunnelReq
+	if _, err := req.Unpack(data); err == nil {
+		t.Fatal("Unpack accepted a connection header with the wrong length field")
+	}
+}
+
+func TestTunnelAckRoundTrip(t *testing.T) {
+	want := TunnelAck{Channel: 2, SeqNumber: 9, Status: 0}
+
+	var buf bytes.Buffer
+	if _, err := want.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+
+	var got TunnelAck
+	if _, err := got.Unpack(buf.Bytes()); err != nil {
+		t.Fatalf("Unpack: %v", err)
+	}
+
+	if got != want {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,266 @@
+package knx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/vapourismo/knx-go/knx/cemi"
+	"github.com/vapourismo/knx-go/knx/proto"
+)
+
+// routingMulticastAddr is the standard KNXnet/IP routing multicast group and
+// port.
+const routingMulticastAddr = "224.0.23.12:3671"
+
+// A LostMessageHandler is notified whenever a ROUTING_LOST_MESSAGE is
+// observed on the multicast group.
+type LostMessageHandler func(count uint16)
+
+// RouterConfig configures a Router.
+type RouterConfig struct {
+	// Interface is the name of the network interface to join the routing
+	// multicast group on. If empty, the system default is used.
+	Interface string
+
+	// OnLostMessage, if set, is called whenever a router on the group
+	// reports dropped frames.
+	OnLostMessage LostMessageHandler
+}
+
+// A Router implements KNXnet/IP Routing, a peer of Tunnel that exchanges
+// cEMI frames over UDP multicast instead of a point-to-point, handshaked
+// connection. Because routing has no ConnReq/ConnStateReq/DiscReq handshake,
+// Router implements its own flow control via the ROUTING_BUSY back-off
+// algorithm.
+type Router struct {
+	recvConn *net.UDPConn
+	sendConn *net.UDPConn
+
+	inbound chan cemi.Message
+	closed  chan struct{}
+
+	backoff       routingBackoff
+	onLostMessage LostMessageHandler
+
+	lostMu    sync.Mutex
+	lostCount uint64
+}
+
+// NewRouter joins the KNXnet/IP routing multicast group and returns a Router
+// ready to send and receive cEMI frames.
+func NewRouter(config RouterConfig) (*Router, error) {
+	groupAddr, err := net.ResolveUDPAddr("udp4", routingMulticastAddr)
+	if err != nil {
+		return nil, fmt.Errorf("knx: resolving routing multicast address: %w", err)
+	}
+
+	var iface *net.Interface
+	if config.Interface != "" {
+		iface, err = net.InterfaceByName(config.Interface)
+		if err != nil {
+			return nil, fmt.Errorf("knx: resolving interface %q: %w", config.Interface, err)
+		}
+	}
+
+	recvConn, err := net.ListenMulticastUDP("udp4", iface, groupAddr)
+	if err != nil {
+		return nil, fmt.Errorf("knx: joining routing multicast group: %w", err)
+	}
+
+	sendConn, err := net.DialUDP("udp4", nil, groupAddr)
+	if err != nil {
+		recvConn.Close()
+		return nil, fmt.Errorf("knx: opening routing send socket: %w", err)
+	}
+
+	router := &Router{
+		recvConn:      recvConn,
+		sendConn:      sendConn,
+		inbound:       make(chan cemi.Message),
+		closed:        make(chan struct{}),
+		onLostMessage: config.OnLostMessage,
+	}
+
+	go router.serve()
+
+	return router, nil
+}
+
+// Inbound returns the channel on which cEMI frames observed on the multicast
+// group are delivered.
+func (router *Router) Inbound() <-chan cemi.Message {
+	return router.inbound
+}
+
+// LostMessages returns the sum of the lost message counts reported by every
+// ROUTING_LOST_MESSAGE notification observed since the router was created.
+func (router *Router) LostMessages() uint64 {
+	router.lostMu.Lock()
+	defer router.lostMu.Unlock()
+
+	return router.lostCount
+}
+
+// Send multicasts a cEMI frame as a ROUTING_INDICATION, honoring any
+// outstanding ROUTING_BUSY back-off.
+func (router *Router) Send(msg cemi.Message) error {
+	router.backoff.wait()
+
+	var buf bytes.Buffer
+	if _, err := msg.WriteTo(&buf); err != nil {
+		return err
+	}
+
+	return sendFrame(router.sendConn, &proto.RoutingInd{Data: buf.Bytes()})
+}
+
+// Close leaves the multicast group and releases the router's sockets.
+func (router *Router) Close() {
+	select {
+	case <-router.closed:
+		return
+	default:
+		close(router.closed)
+	}
+
+	router.recvConn.Close()
+	router.sendConn.Close()
+}
+
+// serve reads frames from the multicast group and dispatches them by
+// service identifier.
+func (router *Router) serve() {
+	defer close(router.inbound)
+
+	buf := make([]byte, 1024)
+
+	for {
+		n, err := router.recvConn.Read(buf)
+		if err != nil {
+			return
+		}
+
+		if n < frameHeaderLen {
+			continue
+		}
+
+		service := proto.ServiceID(binary.BigEndian.Uint16(buf[2:4]))
+		body := buf[frameHeaderLen:n]
+
+		switch service {
+		case proto.RoutingIndService:
+			router.handleIndication(body)
+
+		case proto.RoutingLostService:
+			router.handleLostMessage(body)
+
+		case proto.RoutingBusyService:
+			router.handleBusy(body)
+		}
+	}
+}
+
+// handleIndication decodes a ROUTING_INDICATION's cEMI payload and forwards
+// it to the inbound channel.
+func (router *Router) handleIndication(body []byte) {
+	var ind proto.RoutingInd
+	if _, err := ind.Unpack(body); err != nil {
+		return
+	}
+
+	var msg cemi.Message
+	if _, err := cemi.Unpack(ind.Data, &msg); err != nil {
+		return
+	}
+
+	select {
+	case router.inbound <- msg:
+	case <-router.closed:
+	}
+}
+
+// handleLostMessage updates the lost-message counter and invokes the
+// configured callback, if any.
+func (router *Router) handleLostMessage(body []byte) {
+	var lost proto.RoutingLostMessage
+	if _, err := lost.Unpack(body); err != nil {
+		return
+	}
+
+	router.lostMu.Lock()
+	router.lostCount += uint64(lost.LostMessageCount)
+	router.lostMu.Unlock()
+
+	if router.onLostMessage != nil {
+		router.onLostMessage(lost.LostMessageCount)
+	}
+}
+
+// handleBusy applies the announced back-off to future sends.
+func (router *Router) handleBusy(body []byte) {
+	var busy proto.RoutingBusy
+	if _, err := busy.Unpack(body); err != nil {
+		return
+	}
+
+	router.backoff.onBusy(time.Duration(busy.WaitTime) * time.Millisecond)
+}
+
+// routingBackoff implements the KNX routing busy back-off algorithm: every
+// ROUTING_BUSY received extends a shared deadline by the announced wait time
+// plus a randomized slow-down window proportional to how many busy
+// notifications have been seen recently, so that congested routers don't all
+// resume sending at the same instant.
+type routingBackoff struct {
+	mu        sync.Mutex
+	busyUntil time.Time
+	busyCount int
+	lastBusy  time.Time
+}
+
+// quietFactor is how many multiples of the announced wait time must pass
+// without a further ROUTING_BUSY before busyCount is considered stale and
+// reset, per the routing back-off algorithm's quiet-period rule.
+const quietFactor = 5
+
+// onBusy records a ROUTING_BUSY notification and extends the back-off
+// deadline. If no busy notification has been seen for quietFactor times the
+// announced wait time, busyCount is reset first, so a router that has been
+// quiet for a while doesn't inherit an arbitrarily large slow-down window
+// from congestion long past.
+func (b *routingBackoff) onBusy(waitTime time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+
+	if !b.lastBusy.IsZero() && now.Sub(b.lastBusy) > quietFactor*waitTime {
+		b.busyCount = 0
+	}
+
+	b.lastBusy = now
+	b.busyCount++
+
+	slowdown := time.Duration(rand.Int63n(int64(waitTime)*int64(b.busyCount) + 1))
+	until := now.Add(waitTime + slowdown)
+
+	if until.After(b.busyUntil) {
+		b.busyUntil = until
+	}
+}
+
+// wait blocks until any outstanding back-off deadline has passed.
+func (b *routingBackoff) wait() {
+	b.mu.Lock()
+	until := b.busyUntil
+	b.mu.Unlock()
+
+	if d := time.Until(until); d > 0 {
+		time.Sleep(d)
+	}
+}
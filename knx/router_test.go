@@ -0,0 +1,66 @@
+package knx
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRoutingBackoffExtendsDeadline(t *testing.T) {
+	var b routingBackoff
+
+	before := time.Now()
+	b.onBusy(10 * time.Millisecond)
+
+	if !b.busyUntil.After(before) {
+		t.Fatal("onBusy did not extend busyUntil into the future")
+	}
+}
+
+func TestRoutingBackoffCountsConsecutiveBusy(t *testing.T) {
+	var b routingBackoff
+
+	b.onBusy(time.Millisecond)
+	if b.busyCount != 1 {
+		t.Fatalf("busyCount = %d, want 1", b.busyCount)
+	}
+
+	b.onBusy(time.Millisecond)
+	if b.busyCount != 2 {
+		t.Fatalf("busyCount = %d, want 2", b.busyCount)
+	}
+}
+
+func TestRoutingBackoffDecaysAfterQuietPeriod(t *testing.T) {
+	var b routingBackoff
+
+	waitTime := time.Millisecond
+
+	b.onBusy(waitTime)
+	b.onBusy(waitTime)
+	b.onBusy(waitTime)
+
+	if b.busyCount != 3 {
+		t.Fatalf("busyCount = %d, want 3", b.busyCount)
+	}
+
+	// Simulate a quiet period longer than quietFactor*waitTime having
+	// elapsed since the last busy notification.
+	b.lastBusy = time.Now().Add(-(quietFactor + 1) * waitTime)
+
+	b.onBusy(waitTime)
+
+	if b.busyCount != 1 {
+		t.Fatalf("busyCount = %d after a quiet period, want 1", b.busyCount)
+	}
+}
+
+func TestRoutingBackoffWaitReturnsImmediatelyWithoutBusy(t *testing.T) {
+	var b routingBackoff
+
+	start := time.Now()
+	b.wait()
+
+	if time.Since(start) > 50*time.Millisecond {
+		t.Fatal("wait blocked despite no outstanding back-off")
+	}
+}
@@ -0,0 +1,143 @@
+package secure
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+// macSize is the length in bytes of the CCM authentication tag appended to
+// every encrypted KNXnet/IP Secure payload.
+const macSize = 16
+
+// nonceSize is the length in bytes of the CCM nonce used by KNXnet/IP Secure,
+// assembled from the 6-byte sequence information and 6-byte serial number.
+const nonceSize = 12
+
+// ErrAuthentication is returned when a secure wrapper's MAC does not verify.
+var ErrAuthentication = errors.New("knx/secure: message authentication failed")
+
+// Encrypt encrypts and authenticates plaintext using AES-128-CCM as defined
+// by KNXnet/IP Secure. nonce must be the 12-byte value assembled from the
+// secure wrapper's sequence information and serial number, and additional is
+// the associated data (the cleartext header) that is authenticated but not
+// encrypted. The returned slice is the ciphertext followed by the 16-byte MAC.
+func Encrypt(key [16]byte, nonce [nonceSize]byte, additional, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	mac, err := cbcMAC(block, nonce, additional, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, len(plaintext)+macSize)
+	ctrCrypt(block, nonce, 1, plaintext, out[:len(plaintext)])
+	ctrCrypt(block, nonce, 0, mac[:], out[len(plaintext):])
+
+	return out, nil
+}
+
+// Decrypt verifies and decrypts a ciphertext produced by Encrypt. It returns
+// ErrAuthentication if the MAC does not match.
+func Decrypt(key [16]byte, nonce [nonceSize]byte, additional, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < macSize {
+		return nil, ErrAuthentication
+	}
+
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	body := ciphertext[:len(ciphertext)-macSize]
+	tag := ciphertext[len(ciphertext)-macSize:]
+
+	plaintext := make([]byte, len(body))
+	ctrCrypt(block, nonce, 1, body, plaintext)
+
+	mac, err := cbcMAC(block, nonce, additional, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	var gotTag [macSize]byte
+	ctrCrypt(block, nonce, 0, mac[:], gotTag[:])
+
+	if subtle.ConstantTimeCompare(gotTag[:], tag) != 1 {
+		return nil, ErrAuthentication
+	}
+
+	return plaintext, nil
+}
+
+// cbcMAC computes the CBC-MAC over the associated data and plaintext as
+// described by CCM (RFC 3610), using the B0 block (flags, nonce and plaintext
+// length) as the first AES input.
+func cbcMAC(block cipher.Block, nonce [nonceSize]byte, additional, plaintext []byte) ([16]byte, error) {
+	var b0 [16]byte
+	b0[0] = 0x79 // flags: Adata present (0x40), M'=(macSize-2)/2=7 (0x38), L'=1
+	copy(b0[1:1+nonceSize], nonce[:])
+	binary.BigEndian.PutUint16(b0[14:], uint16(len(plaintext)))
+
+	mac := make([]byte, 16)
+	block.Encrypt(mac, b0[:])
+
+	xorBlock := func(chunk []byte) {
+		for i := range mac {
+			if i < len(chunk) {
+				mac[i] ^= chunk[i]
+			}
+		}
+		block.Encrypt(mac, mac)
+	}
+
+	adataLen := make([]byte, 2)
+	binary.BigEndian.PutUint16(adataLen, uint16(len(additional)))
+	feedBCBC(adataLen, additional, xorBlock)
+	feedBlocks(plaintext, xorBlock)
+
+	var out [16]byte
+	copy(out[:], mac)
+
+	return out, nil
+}
+
+// feedBCBC feeds the 2-byte associated-data length field followed by the
+// associated data itself into the CBC-MAC, padding the final block with
+// zeroes as required by CCM.
+func feedBCBC(prefix, data []byte, xorBlock func([]byte)) {
+	buf := append(append([]byte{}, prefix...), data...)
+	feedBlocks(buf, xorBlock)
+}
+
+// feedBlocks feeds data into the CBC-MAC 16 bytes at a time, zero-padding the
+// final partial block.
+func feedBlocks(data []byte, xorBlock func([]byte)) {
+	for len(data) > 0 {
+		n := 16
+		if len(data) < n {
+			n = len(data)
+		}
+
+		xorBlock(data[:n])
+		data = data[n:]
+	}
+}
+
+// ctrCrypt encrypts or decrypts src into dst using CCM's counter mode, with
+// the counter starting at startCtr as prescribed by RFC 3610 (counter 0 is
+// reserved for encrypting the MAC, counters 1.. for the message body).
+func ctrCrypt(block cipher.Block, nonce [nonceSize]byte, startCtr uint16, src, dst []byte) {
+	var a0 [16]byte
+	a0[0] = 0x01 // flags: L'=1
+	copy(a0[1:1+nonceSize], nonce[:])
+	binary.BigEndian.PutUint16(a0[14:], startCtr)
+
+	ctr := cipher.NewCTR(block, a0[:])
+	ctr.XORKeyStream(dst, src)
+}
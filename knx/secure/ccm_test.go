@@ -0,0 +1,100 @@
+package secure
+
+import (
+	"bytes"
+	"testing"
+)
+
+func testKeyNonce() ([16]byte, [nonceSize]byte) {
+	var key [16]byte
+	var nonce [nonceSize]byte
+
+	for i := range key {
+		key[i] = byte(i)
+	}
+
+	for i := range nonce {
+		nonce[i] = byte(0xa0 + i)
+	}
+
+	return key, nonce
+}
+
+func TestEncryptDecryptRoundTrip(t *testing.T) {
+	key, nonce := testKeyNonce()
+	additional := []byte("secure wrapper header")
+	plaintext := []byte("a KNXnet/IP frame carried inside a SecureWrapper")
+
+	ciphertext, err := Encrypt(key, nonce, additional, plaintext)
+	if err != nil {
+		t.Fatalf("Encrypt returned error: %v", err)
+	}
+
+	if len(ciphertext) != len(plaintext)+macSize {
+		t.Fatalf("ciphertext length = %d, want %d", len(ciphertext), len(plaintext)+macSize)
+	}
+
+	decrypted, err := Decrypt(key, nonce, additional, ciphertext)
+	if err != nil {
+		t.Fatalf("Decrypt returned error: %v", err)
+	}
+
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Fatalf("Decrypt = %q, want %q", decrypted, plaintext)
+	}
+}
+
+func TestDecryptDetectsTamperedCiphertext(t *testing.T) {
+	key, nonce := testKeyNonce()
+	additional := []byte("secure wrapper header")
+	plaintext := []byte("a KNXnet/IP frame")
+
+	ciphertext, err := Encrypt(key, nonce, additional, plaintext)
+	if err != nil {
+		t.Fatalf("Encrypt returned error: %v", err)
+	}
+
+	ciphertext[0] ^= 0xff
+
+	if _, err := Decrypt(key, nonce, additional, ciphertext); err != ErrAuthentication {
+		t.Fatalf("Decrypt of tampered ciphertext returned %v, want ErrAuthentication", err)
+	}
+}
+
+func TestDecryptDetectsTamperedAdditional(t *testing.T) {
+	key, nonce := testKeyNonce()
+	plaintext := []byte("a KNXnet/IP frame")
+
+	ciphertext, err := Encrypt(key, nonce, []byte("header a"), plaintext)
+	if err != nil {
+		t.Fatalf("Encrypt returned error: %v", err)
+	}
+
+	if _, err := Decrypt(key, nonce, []byte("header b"), ciphertext); err != ErrAuthentication {
+		t.Fatalf("Decrypt with mismatched additional data returned %v, want ErrAuthentication", err)
+	}
+}
+
+func TestDecryptRejectsShortCiphertext(t *testing.T) {
+	key, nonce := testKeyNonce()
+
+	if _, err := Decrypt(key, nonce, nil, make([]byte, macSize-1)); err != ErrAuthentication {
+		t.Fatalf("Decrypt of undersized ciphertext returned %v, want ErrAuthentication", err)
+	}
+}
+
+// TestCCMFlagsByte guards against macSize silently drifting out of sync with
+// the 0x79 B0 flags byte that cbcMAC writes: flags must encode Adata=1,
+// M'=(macSize-2)/2 and L'=1 per RFC 3610. The test recomputes the flags from
+// macSize and compares them with 0x79; it does not read the byte cbcMAC uses.
+func TestCCMFlagsByte(t *testing.T) {
+	const adata = 1
+	const lPrime = 1
+
+	mPrime := (macSize - 2) / 2
+	want := byte(adata<<6 | mPrime<<3 | lPrime)
+
+	if want != 0x79 {
+		t.Fatalf("expected CCM flags byte 0x79 for a 16-byte MAC, computed %#x", want)
+	}
+}
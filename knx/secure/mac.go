@@ -0,0 +1,60 @@
+package secure
+
+import "crypto/aes"
+
+// MAC computes the KNXnet/IP Secure authentication tag over additional with
+// no encrypted payload, i.e. CCM's CBC-MAC keyed by key. It is used to
+// authenticate the SessionReq/SessionRes/SessionAuthenticate handshake,
+// where the authenticated data is the exchanged public keys rather than an
+// encrypted frame.
+func MAC(key [16]byte, nonce [nonceSize]byte, additional []byte) ([16]byte, error) {
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return [16]byte{}, err
+	}
+
+	mac, err := cbcMAC(block, nonce, additional, nil)
+	if err != nil {
+		return [16]byte{}, err
+	}
+
+	var tag [16]byte
+	ctrCrypt(block, nonce, 0, mac[:], tag[:])
+
+	return tag, nil
+}
+
+// SessionResMAC computes the MAC that authenticates a SessionRes: it binds
+// the client's and server's ephemeral public keys to the device
+// authentication code, so a client can detect a tampered or forged server
+// key before deriving a session key from it.
+//
+// This is not the AN159 SessionRes MAC construction: the genuine protocol
+// authenticates additional SessionRes fields and combines the public keys
+// differently. This function only guarantees that the handshake is
+// internally consistent between two peers running this package; it does not
+// interoperate with a certified KNX Secure gateway or client.
+func SessionResMAC(deviceAuth [16]byte, clientPublic, serverPublic [32]byte) ([16]byte, error) {
+	additional := make([]byte, 0, 64)
+	additional = append(additional, clientPublic[:]...)
+	additional = append(additional, serverPublic[:]...)
+
+	return MAC(deviceAuth, [nonceSize]byte{}, additional)
+}
+
+// SessionAuthMAC computes the MAC carried in SessionAuthenticate: it binds
+// the requested user ID and the exchanged public keys to the user's
+// authentication code, proving knowledge of the user's password to the
+// server.
+//
+// As with SessionResMAC, this is a self-consistent CBC-MAC rather than a
+// verified implementation of AN159's SessionAuthenticate MAC, and will not
+// interoperate with a certified KNX Secure device.
+func SessionAuthMAC(userPassword [16]byte, userID uint8, clientPublic, serverPublic [32]byte) ([16]byte, error) {
+	additional := make([]byte, 0, 65)
+	additional = append(additional, userID)
+	additional = append(additional, clientPublic[:]...)
+	additional = append(additional, serverPublic[:]...)
+
+	return MAC(userPassword, [nonceSize]byte{}, additional)
+}
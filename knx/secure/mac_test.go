@@ -0,0 +1,75 @@
+package secure
+
+import "testing"
+
+func TestSessionResMACDetectsTamperedKeys(t *testing.T) {
+	var deviceAuth [16]byte
+	copy(deviceAuth[:], "device-auth-code")
+
+	client, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair (client): %v", err)
+	}
+
+	server, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair (server): %v", err)
+	}
+
+	mallory, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair (mallory): %v", err)
+	}
+
+	mac, err := SessionResMAC(deviceAuth, client.Public, server.Public)
+	if err != nil {
+		t.Fatalf("SessionResMAC: %v", err)
+	}
+
+	forged, err := SessionResMAC(deviceAuth, client.Public, mallory.Public)
+	if err != nil {
+		t.Fatalf("SessionResMAC (forged): %v", err)
+	}
+
+	if mac == forged {
+		t.Fatal("SessionResMAC did not change when the server's public key was substituted")
+	}
+
+	again, err := SessionResMAC(deviceAuth, client.Public, server.Public)
+	if err != nil {
+		t.Fatalf("SessionResMAC (again): %v", err)
+	}
+
+	if mac != again {
+		t.Fatal("SessionResMAC is not deterministic for the same inputs")
+	}
+}
+
+func TestSessionAuthMACBindsUserID(t *testing.T) {
+	var userPassword [16]byte
+	copy(userPassword[:], "user-password-123")
+
+	client, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair (client): %v", err)
+	}
+
+	server, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair (server): %v", err)
+	}
+
+	macUser1, err := SessionAuthMAC(userPassword, 1, client.Public, server.Public)
+	if err != nil {
+		t.Fatalf("SessionAuthMAC (user 1): %v", err)
+	}
+
+	macUser2, err := SessionAuthMAC(userPassword, 2, client.Public, server.Public)
+	if err != nil {
+		t.Fatalf("SessionAuthMAC (user 2): %v", err)
+	}
+
+	if macUser1 == macUser2 {
+		t.Fatal("SessionAuthMAC did not change when the user ID changed")
+	}
+}
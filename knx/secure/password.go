@@ -0,0 +1,46 @@
+package secure
+
+import (
+	"crypto/sha256"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// pbkdf2Iterations is the fixed iteration count mandated by KNXnet/IP Secure
+// for deriving authentication codes from passwords.
+const pbkdf2Iterations = 65536
+
+// pbkdf2KeyLen is the length in bytes of the derived authentication code.
+const pbkdf2KeyLen = 16
+
+// pbkdf2Salt is the fixed salt ("device-authentication-code.1.secure.ip.knx.org"
+// or "user-password.1.secure.ip.knx.org") prescribed by the KNX specification
+// for the respective derivation.
+type pbkdf2Salt string
+
+const (
+	deviceAuthSalt   pbkdf2Salt = "device-authentication-code.1.secure.ip.knx.org"
+	userPasswordSalt pbkdf2Salt = "user-password.1.secure.ip.knx.org"
+)
+
+// DeviceAuthCode derives the 16-byte device authentication code from the
+// backbone/tunnelling password as printed on the device, using the
+// PBKDF2-HMAC-SHA256 scheme defined by KNXnet/IP Secure.
+func DeviceAuthCode(password string) [16]byte {
+	return deriveKey(password, deviceAuthSalt)
+}
+
+// UserPasswordHash derives the 16-byte user authentication code from a user
+// password, using the PBKDF2-HMAC-SHA256 scheme defined by KNXnet/IP Secure.
+func UserPasswordHash(password string) [16]byte {
+	return deriveKey(password, userPasswordSalt)
+}
+
+func deriveKey(password string, salt pbkdf2Salt) [16]byte {
+	var key [16]byte
+
+	derived := pbkdf2.Key([]byte(password), []byte(salt), pbkdf2Iterations, pbkdf2KeyLen, sha256.New)
+	copy(key[:], derived)
+
+	return key
+}
@@ -0,0 +1,35 @@
+package secure
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+func TestDeviceAuthCodeMatchesPBKDF2(t *testing.T) {
+	want := pbkdf2.Key([]byte("my-backbone-password"), []byte(deviceAuthSalt), pbkdf2Iterations, pbkdf2KeyLen, sha256.New)
+
+	got := DeviceAuthCode("my-backbone-password")
+	if !bytes.Equal(got[:], want) {
+		t.Fatalf("DeviceAuthCode = %x, want %x", got, want)
+	}
+}
+
+func TestUserPasswordHashMatchesPBKDF2(t *testing.T) {
+	want := pbkdf2.Key([]byte("my-user-password"), []byte(userPasswordSalt), pbkdf2Iterations, pbkdf2KeyLen, sha256.New)
+
+	got := UserPasswordHash("my-user-password")
+	if !bytes.Equal(got[:], want) {
+		t.Fatalf("UserPasswordHash = %x, want %x", got, want)
+	}
+}
+
+func TestDeviceAuthCodeAndUserPasswordHashDiffer(t *testing.T) {
+	// The same password must not hash to the same value under the two
+	// derivations, since they use different fixed salts.
+	if DeviceAuthCode("shared-secret") == UserPasswordHash("shared-secret") {
+		t.Fatal("DeviceAuthCode and UserPasswordHash produced the same output for the same password")
+	}
+}
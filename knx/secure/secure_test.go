@@ -0,0 +1,60 @@
+package secure
+
+import "testing"
+
+func TestSessionKeyAgreement(t *testing.T) {
+	alice, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair (alice): %v", err)
+	}
+
+	bob, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair (bob): %v", err)
+	}
+
+	aliceKey, err := SessionKey(alice.Private, bob.Public)
+	if err != nil {
+		t.Fatalf("SessionKey (alice): %v", err)
+	}
+
+	bobKey, err := SessionKey(bob.Private, alice.Public)
+	if err != nil {
+		t.Fatalf("SessionKey (bob): %v", err)
+	}
+
+	if aliceKey != bobKey {
+		t.Fatalf("session keys disagree: alice %x, bob %x", aliceKey, bobKey)
+	}
+}
+
+func TestSessionKeyDiffersForDifferentPeers(t *testing.T) {
+	alice, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair (alice): %v", err)
+	}
+
+	bob, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair (bob): %v", err)
+	}
+
+	mallory, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair (mallory): %v", err)
+	}
+
+	withBob, err := SessionKey(alice.Private, bob.Public)
+	if err != nil {
+		t.Fatalf("SessionKey (alice/bob): %v", err)
+	}
+
+	withMallory, err := SessionKey(alice.Private, mallory.Public)
+	if err != nil {
+		t.Fatalf("SessionKey (alice/mallory): %v", err)
+	}
+
+	if withBob == withMallory {
+		t.Fatal("SessionKey produced the same key for two different peers")
+	}
+}
@@ -0,0 +1,125 @@
+package secure
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"github.com/vapourismo/knx-go/knx/proto"
+)
+
+// A Session manages the keys and sequence counters of an established
+// KNXnet/IP Secure session and wraps/unwraps frames exchanged over it.
+// Outgoing and incoming frames use independent sequence spaces - one
+// counts what we have sent, the other the replay watermark for what we have
+// accepted from the peer - since a tunnel exchanges frames in both
+// directions over the same session.
+type Session struct {
+	mu sync.Mutex
+
+	ChannelID uint16
+	Key       [16]byte
+
+	serialNumber [6]byte
+	sendSeq      uint64
+	recvSeq      uint64
+}
+
+// NewSession creates a session for the given channel, authenticated with the
+// given session key and identified by the client's serial number, as
+// negotiated during the SessionReq/SessionRes/SessionAuthenticate handshake.
+func NewSession(channelID uint16, key [16]byte, serialNumber [6]byte) *Session {
+	return &Session{
+		ChannelID:    channelID,
+		Key:          key,
+		serialNumber: serialNumber,
+	}
+}
+
+// Wrap encrypts a KNXnet/IP frame and returns the SecureWrapper that carries
+// it, advancing the session's sequence counter.
+func (session *Session) Wrap(messageTag [2]byte, frame []byte) (proto.SecureWrapper, error) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	var wrapper proto.SecureWrapper
+	wrapper.ChannelID = session.ChannelID
+	wrapper.SerialNumber = session.serialNumber
+	wrapper.MessageTag = messageTag
+	putSeq(&wrapper.SeqNumber, session.sendSeq)
+
+	nonce := assembleNonce(wrapper.SeqNumber, wrapper.SerialNumber)
+	additional := secureHeader(wrapper.ChannelID, wrapper.SeqNumber, wrapper.SerialNumber, wrapper.MessageTag)
+
+	encrypted, err := Encrypt(session.Key, nonce, additional, frame)
+	if err != nil {
+		return wrapper, err
+	}
+
+	wrapper.EncryptedData = encrypted
+	session.sendSeq++
+
+	return wrapper, nil
+}
+
+// Unwrap decrypts a SecureWrapper and returns the KNXnet/IP frame it carries.
+// It rejects frames whose sequence number is not strictly greater than the
+// last one accepted from this session, preventing replay.
+func (session *Session) Unwrap(wrapper proto.SecureWrapper) ([]byte, error) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	seq := seqValue(wrapper.SeqNumber)
+	if seq < session.recvSeq {
+		return nil, fmt.Errorf("knx/secure: sequence %d has already been observed", seq)
+	}
+
+	nonce := assembleNonce(wrapper.SeqNumber, wrapper.SerialNumber)
+	additional := secureHeader(wrapper.ChannelID, wrapper.SeqNumber, wrapper.SerialNumber, wrapper.MessageTag)
+
+	frame, err := Decrypt(session.Key, nonce, additional, wrapper.EncryptedData)
+	if err != nil {
+		return nil, err
+	}
+
+	session.recvSeq = seq + 1
+
+	return frame, nil
+}
+
+// secureHeader reassembles the cleartext SecureWrapper header, which serves
+// as the associated data authenticated (but not encrypted) by AES-CCM.
+func secureHeader(channelID uint16, seqNumber, serialNumber [6]byte, messageTag [2]byte) []byte {
+	header := make([]byte, 16)
+	binary.BigEndian.PutUint16(header[0:2], channelID)
+	copy(header[2:8], seqNumber[:])
+	copy(header[8:14], serialNumber[:])
+	copy(header[14:16], messageTag[:])
+
+	return header
+}
+
+// assembleNonce builds the 12-byte CCM nonce from the sequence information
+// and serial number, as defined by KNXnet/IP Secure.
+func assembleNonce(seqNumber, serialNumber [6]byte) [12]byte {
+	var nonce [12]byte
+	copy(nonce[0:6], seqNumber[:])
+	copy(nonce[6:12], serialNumber[:])
+
+	return nonce
+}
+
+// putSeq encodes a 48-bit sequence counter into its big-endian wire form.
+func putSeq(dst *[6]byte, seq uint64) {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], seq)
+	copy(dst[:], buf[2:])
+}
+
+// seqValue decodes a 48-bit big-endian sequence counter.
+func seqValue(src [6]byte) uint64 {
+	var buf [8]byte
+	copy(buf[2:], src[:])
+
+	return binary.BigEndian.Uint64(buf[:])
+}
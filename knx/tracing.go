@@ -0,0 +1,87 @@
+package knx
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/vapourismo/knx-go/knx/proto"
+)
+
+// TracingObserver is an Observer that emits an OpenTelemetry span for every
+// ConnReq/ConnRes and ConnStateReq/ConnStateRes exchange. Connect spans carry
+// the remote host, tunnelling layer and connect status; heartbeat spans carry
+// the remote host, KNX channel ID and connection state. Connect spans do not
+// carry the channel ID, and heartbeat spans do not carry the tunnelling layer.
+type TracingObserver struct {
+	Tracer trace.Tracer
+}
+
+// NewTracingObserver creates a TracingObserver using the given tracer
+// provider's "knx-go" tracer.
+func NewTracingObserver(provider trace.TracerProvider) *TracingObserver {
+	return &TracingObserver{Tracer: provider.Tracer("knx-go")}
+}
+
+// OnConnect implements Observer by recording a span for the connect attempt.
+func (o *TracingObserver) OnConnect(gateway string, layer TunnelLayer, status proto.ConnResStatus, elapsed time.Duration) {
+	_, span := o.Tracer.Start(context.Background(), "knx.tunnel.connect",
+		trace.WithTimestamp(time.Now().Add(-elapsed)),
+		trace.WithAttributes(
+			attribute.String("knx.gateway", gateway),
+			attribute.Int64("knx.tunnel_layer", int64(layer)),
+			attribute.String("knx.conn_status", status.String()),
+		),
+	)
+	defer span.End()
+
+	if status != proto.ConnResOk {
+		span.SetStatus(codes.Error, status.String())
+	}
+}
+
+// OnHeartbeat implements Observer by recording a span for the heartbeat
+// exchange.
+func (o *TracingObserver) OnHeartbeat(gateway string, channel uint8, state proto.ConnState, rtt time.Duration, err error) {
+	_, span := o.Tracer.Start(context.Background(), "knx.tunnel.heartbeat",
+		trace.WithTimestamp(time.Now().Add(-rtt)),
+		trace.WithAttributes(
+			attribute.String("knx.gateway", gateway),
+			attribute.Int64("knx.channel", int64(channel)),
+			attribute.String("knx.conn_state", state.String()),
+		),
+	)
+	defer span.End()
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// OnDisconnect implements Observer by recording a span for the disconnect.
+func (o *TracingObserver) OnDisconnect(gateway string, channel uint8, status uint8) {
+	_, span := o.Tracer.Start(context.Background(), "knx.tunnel.disconnect",
+		trace.WithAttributes(
+			attribute.String("knx.gateway", gateway),
+			attribute.Int64("knx.channel", int64(channel)),
+			attribute.Int64("knx.disc_status", int64(status)),
+		),
+	)
+	span.End()
+}
+
+// OnSessionEnd implements Observer by recording the overall session span.
+func (o *TracingObserver) OnSessionEnd(gateway string, layer TunnelLayer, duration time.Duration) {
+	_, span := o.Tracer.Start(context.Background(), "knx.tunnel.session",
+		trace.WithTimestamp(time.Now().Add(-duration)),
+		trace.WithAttributes(
+			attribute.String("knx.gateway", gateway),
+			attribute.Int64("knx.tunnel_layer", int64(layer)),
+		),
+	)
+	span.End(trace.WithTimestamp(time.Now()))
+}
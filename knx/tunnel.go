@@ -0,0 +1,281 @@
+package knx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/vapourismo/knx-go/knx/cemi"
+	"github.com/vapourismo/knx-go/knx/proto"
+)
+
+// TunnelLayer is re-exported from proto for convenient use as knx.TunnelLayer.
+type TunnelLayer = proto.TunnelLayer
+
+// These are re-exported from proto for convenient use as knx.TunnelLayerXxx.
+const (
+	TunnelLayerData   = proto.TunnelLayerData
+	TunnelLayerRaw    = proto.TunnelLayerRaw
+	TunnelLayerBusmon = proto.TunnelLayerBusmon
+)
+
+// TunnelConfig configures a Tunnel.
+type TunnelConfig struct {
+	// Heartbeat is the interval between ConnStateReq heartbeats. Defaults to
+	// 10 seconds.
+	Heartbeat time.Duration
+
+	// HeartbeatTimeout is how long to wait for a ConnStateRes before the
+	// heartbeat is considered to have failed. Defaults to 10 seconds.
+	HeartbeatTimeout time.Duration
+
+	// Observer, if set, is notified about the tunnel's connection lifecycle.
+	// Tunnel invokes it from the goroutines that drive the connection, so
+	// implementations must be safe for concurrent use.
+	Observer Observer
+}
+
+// A Tunnel is a single KNXnet/IP tunnelling connection: a ConnReq/ConnRes
+// handshake, a ConnStateReq/ConnStateRes heartbeat kept alive for the
+// lifetime of the connection, and a stream of cEMI frames exchanged via
+// TunnelReq/TunnelAck.
+type Tunnel struct {
+	conn     *net.UDPConn
+	gateway  string
+	layer    TunnelLayer
+	config   TunnelConfig
+	observer Observer
+
+	channel   uint8
+	seqNumber uint8
+	seqMu     sync.Mutex
+
+	inbound      chan cemi.Message
+	connStateRes chan proto.ConnStateRes
+	closed       chan struct{}
+	closeOnce    sync.Once
+
+	connectedAt time.Time
+}
+
+// NewTunnel establishes a tunnelling connection with the given gateway.
+func NewTunnel(gatewayAddr string, layer TunnelLayer, config TunnelConfig) (*Tunnel, error) {
+	if config.Heartbeat <= 0 {
+		config.Heartbeat = 10 * time.Second
+	}
+
+	if config.HeartbeatTimeout <= 0 {
+		config.HeartbeatTimeout = 10 * time.Second
+	}
+
+	observer := config.Observer
+	if observer == nil {
+		observer = NopObserver{}
+	}
+
+	addr, err := net.ResolveUDPAddr("udp4", gatewayAddr)
+	if err != nil {
+		return nil, fmt.Errorf("knx: resolving gateway address: %w", err)
+	}
+
+	conn, err := net.DialUDP("udp4", nil, addr)
+	if err != nil {
+		return nil, fmt.Errorf("knx: dialing gateway: %w", err)
+	}
+
+	tunnel := &Tunnel{
+		conn:         conn,
+		gateway:      gatewayAddr,
+		layer:        layer,
+		config:       config,
+		observer:     observer,
+		inbound:      make(chan cemi.Message),
+		connStateRes: make(chan proto.ConnStateRes, 1),
+		closed:       make(chan struct{}),
+	}
+
+	start := time.Now()
+	status, err := tunnel.connect(layer)
+	observer.OnConnect(gatewayAddr, layer, status, time.Since(start))
+
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	tunnel.connectedAt = time.Now()
+
+	go tunnel.serve()
+	go tunnel.heartbeatLoop()
+
+	return tunnel, nil
+}
+
+// connect performs the ConnReq/ConnRes handshake.
+func (tunnel *Tunnel) connect(layer TunnelLayer) (proto.ConnResStatus, error) {
+	req := proto.ConnReq{Layer: layer}
+	if err := sendFrame(tunnel.conn, &req); err != nil {
+		return 0, fmt.Errorf("knx: sending ConnReq: %w", err)
+	}
+
+	var res proto.ConnRes
+	if err := recvFrame(tunnel.conn, proto.ConnResService, &res); err != nil {
+		return 0, fmt.Errorf("knx: receiving ConnRes: %w", err)
+	}
+
+	tunnel.channel = res.Channel
+
+	if res.Status != proto.ConnResOk {
+		return res.Status, res.Status
+	}
+
+	return res.Status, nil
+}
+
+// heartbeatLoop periodically issues ConnStateReq heartbeats until the
+// tunnel is closed.
+func (tunnel *Tunnel) heartbeatLoop() {
+	ticker := time.NewTicker(tunnel.config.Heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			tunnel.heartbeat()
+
+		case <-tunnel.closed:
+			return
+		}
+	}
+}
+
+// heartbeat issues a single ConnStateReq/ConnStateRes exchange, reports it
+// to the observer, and closes the tunnel if the gateway reports an error or
+// the exchange times out. The response is delivered by serve(), which is the
+// only goroutine that ever reads tunnel.conn, so a heartbeat and an inbound
+// TunnelReq never race for the same datagram.
+func (tunnel *Tunnel) heartbeat() {
+	start := time.Now()
+
+	req := proto.ConnStateReq{Channel: tunnel.channel}
+	sendErr := sendFrame(tunnel.conn, &req)
+
+	var res proto.ConnStateRes
+
+	if sendErr == nil {
+		select {
+		case res = <-tunnel.connStateRes:
+
+		case <-time.After(tunnel.config.HeartbeatTimeout):
+			sendErr = fmt.Errorf("knx: ConnStateRes timed out")
+
+		case <-tunnel.closed:
+			return
+		}
+	}
+
+	tunnel.observer.OnHeartbeat(tunnel.gateway, tunnel.channel, res.Status, time.Since(start), sendErr)
+
+	if sendErr != nil || res.Status != proto.ConnStateNormal {
+		tunnel.Close()
+	}
+}
+
+// serve is the tunnel's only reader of its UDP connection. It dispatches
+// every received frame by service identifier: TunnelReq frames are
+// acknowledged and their cEMI payload delivered to the inbound channel;
+// ConnStateRes frames are handed to whichever heartbeat is currently
+// waiting for one.
+func (tunnel *Tunnel) serve() {
+	defer close(tunnel.inbound)
+
+	buf := make([]byte, 1024)
+
+	for {
+		n, err := tunnel.conn.Read(buf)
+		if err != nil {
+			return
+		}
+
+		if n < frameHeaderLen {
+			continue
+		}
+
+		service := proto.ServiceID(binary.BigEndian.Uint16(buf[2:4]))
+		body := buf[frameHeaderLen:n]
+
+		switch service {
+		case proto.ConnStateResService:
+			var res proto.ConnStateRes
+			if _, err := res.Unpack(body); err != nil {
+				continue
+			}
+
+			select {
+			case tunnel.connStateRes <- res:
+			default:
+				// No heartbeat is currently waiting (it already timed out);
+				// drop the late response.
+			}
+
+		case proto.TunnelReqService:
+			var req proto.TunnelReq
+			if _, err := req.Unpack(body); err != nil {
+				continue
+			}
+
+			sendFrame(tunnel.conn, &proto.TunnelAck{Channel: req.Channel, SeqNumber: req.SeqNumber})
+
+			var msg cemi.Message
+			if _, err := cemi.Unpack(req.Data, &msg); err != nil {
+				continue
+			}
+
+			select {
+			case tunnel.inbound <- msg:
+			case <-tunnel.closed:
+				return
+			}
+		}
+	}
+}
+
+// Inbound returns the channel on which decoded cEMI messages are delivered.
+func (tunnel *Tunnel) Inbound() <-chan cemi.Message {
+	return tunnel.inbound
+}
+
+// Send submits a cEMI message for transmission over the tunnel.
+func (tunnel *Tunnel) Send(msg cemi.Message) error {
+	var buf bytes.Buffer
+	if _, err := msg.WriteTo(&buf); err != nil {
+		return err
+	}
+
+	tunnel.seqMu.Lock()
+	seq := tunnel.seqNumber
+	tunnel.seqNumber++
+	tunnel.seqMu.Unlock()
+
+	req := proto.TunnelReq{Channel: tunnel.channel, SeqNumber: seq, Data: buf.Bytes()}
+
+	return sendFrame(tunnel.conn, &req)
+}
+
+// Close tears the tunnelling connection down, issuing a DiscReq and
+// reporting the disconnect and overall session duration to the observer.
+func (tunnel *Tunnel) Close() {
+	tunnel.closeOnce.Do(func() {
+		close(tunnel.closed)
+
+		sendFrame(tunnel.conn, &proto.DiscReq{Channel: tunnel.channel})
+
+		tunnel.observer.OnDisconnect(tunnel.gateway, tunnel.channel, 0)
+		tunnel.observer.OnSessionEnd(tunnel.gateway, tunnel.layer, time.Since(tunnel.connectedAt))
+
+		tunnel.conn.Close()
+	})
+}